@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 
@@ -19,6 +20,19 @@ func run() int {
 	cmd := cli.NewCommand(filepath.Base(os.Args[0]))
 
 	if err := cmd.Execute(); err != nil {
+		// the persistent post run (removing the temporary files) is skipped for the failed commands
+		_ = cmd.PersistentPostRunE(cmd, nil)
+
+		// some commands (e.g. healthcheck) report their own exit code
+		var ec interface{ ExitCode() int }
+		if errors.As(err, &ec) {
+			if err.Error() != "" {
+				_, _ = color.New(color.FgHiRed, color.Bold).Fprintln(os.Stderr, err.Error())
+			}
+
+			return ec.ExitCode()
+		}
+
 		_, _ = color.New(color.FgHiRed, color.Bold).Fprintln(os.Stderr, err.Error())
 
 		return 1
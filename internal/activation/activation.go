@@ -0,0 +1,230 @@
+// Package activation adopts listening sockets passed by systemd socket activation or by a parent launcher with the
+// same protocol (LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES environment variables, descriptors start from 3).
+//
+// Plugins bind their listeners themselves (sdk utils.CreateListener takes no net.Listener), so the activated sockets
+// are bridged: every `fd://name` address in the configuration is replaced with a unix socket in a private directory,
+// and connections accepted on the activated socket are forwarded to it. Both TCP and unix activated sockets are
+// bridged. Plugins see the unix socket peer instead of the client address.
+package activation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// Scheme of the configuration addresses referencing activated sockets: fd://http
+	Scheme string = "fd://"
+
+	envPID   string = "LISTEN_PID"
+	envFDs   string = "LISTEN_FDS"
+	envNames string = "LISTEN_FDNAMES"
+
+	// first passed descriptor (after stdin, stdout and stderr)
+	firstFD int = 3
+)
+
+// Listeners returns activated listeners by name. Unnamed descriptors are named by their number (fd://3). The
+// environment is cleared, so the workers do not inherit it, and the original descriptors are closed.
+func Listeners() (map[string]net.Listener, error) {
+	defer func() {
+		_ = os.Unsetenv(envPID)
+		_ = os.Unsetenv(envFDs)
+		_ = os.Unsetenv(envNames)
+	}()
+
+	if os.Getenv(envPID) != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(os.Getenv(envFDs))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+
+	var names []string
+	if v := os.Getenv(envNames); v != "" {
+		names = strings.Split(v, ":")
+	}
+
+	listeners := make(map[string]net.Listener, n)
+
+	for i := 0; i < n; i++ {
+		fd := firstFD + i
+
+		name := strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" && names[i] != "unknown" {
+			name = names[i]
+		}
+
+		f := os.NewFile(uintptr(fd), name)
+		l, errL := net.FileListener(f)
+		_ = f.Close()
+
+		if errL != nil {
+			closeAll(listeners)
+
+			return nil, fmt.Errorf("activated descriptor %d (%s): %w", fd, name, errL)
+		}
+
+		if _, ok := listeners[name]; ok {
+			_ = l.Close()
+			closeAll(listeners)
+
+			return nil, fmt.Errorf("duplicate activated descriptor name `%s`", name)
+		}
+
+		listeners[name] = l
+	}
+
+	return listeners, nil
+}
+
+// Bridge forwards connections accepted on the activated listeners to the unix sockets bound by plugins.
+type Bridge struct {
+	dir       string
+	listeners map[string]net.Listener
+	wg        sync.WaitGroup
+}
+
+// NewBridge creates the private directory for the plugins' unix sockets.
+func NewBridge(listeners map[string]net.Listener) (*Bridge, error) {
+	dir, err := os.MkdirTemp("", "rr-activation-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bridge{dir: dir, listeners: listeners}, nil
+}
+
+// Address returns the unix socket DSN which replaces `fd://name` in the configuration.
+func (b *Bridge) Address(name string) (string, bool) {
+	if _, ok := b.listeners[name]; !ok {
+		return "", false
+	}
+
+	return "unix://" + filepath.Join(b.dir, name+".sock"), true
+}
+
+// Overrides returns `key=value` configuration overrides replacing all `fd://name` values with the bridged unix
+// sockets. Nil bridge means that nothing was activated, so any `fd://` reference is an error.
+func (b *Bridge) Overrides(settings map[string]any) ([]string, error) {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var overrides []string
+
+	for _, key := range keys {
+		val, ok := settings[key].(string)
+		if !ok || !strings.HasPrefix(val, Scheme) {
+			continue
+		}
+
+		name := strings.TrimPrefix(val, Scheme)
+		if b == nil {
+			return nil, fmt.Errorf("%s: `%s` is not activated, no LISTEN_FDS passed", key, val)
+		}
+
+		addr, ok := b.Address(name)
+		if !ok {
+			return nil, fmt.Errorf("%s: `%s` is not activated, available: %s", key, val, strings.Join(b.names(), ", "))
+		}
+
+		overrides = append(overrides, key+"="+addr)
+	}
+
+	return overrides, nil
+}
+
+// Start accepts connections on all activated listeners until Stop.
+func (b *Bridge) Start() {
+	for name, l := range b.listeners {
+		b.wg.Add(1)
+
+		go func(path string, l net.Listener) {
+			defer b.wg.Done()
+
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					var ne net.Error
+					if errors.As(err, &ne) && ne.Timeout() {
+						continue
+					}
+
+					return
+				}
+
+				go forward(conn, path)
+			}
+		}(filepath.Join(b.dir, name+".sock"), l)
+	}
+}
+
+// Stop closes the activated listeners and removes the sockets directory.
+func (b *Bridge) Stop() {
+	closeAll(b.listeners)
+	b.wg.Wait()
+
+	_ = os.RemoveAll(b.dir)
+}
+
+func (b *Bridge) names() []string {
+	names := make([]string, 0, len(b.listeners))
+	for name := range b.listeners {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// forward copies data in both directions. When the plugin socket is not bound (plugin is disabled or the container
+// is being reloaded) the client connection is dropped.
+func forward(client net.Conn, path string) {
+	defer func() { _ = client.Close() }()
+
+	upstream, err := net.Dial("unix", path)
+	if err != nil {
+		return
+	}
+
+	defer func() { _ = upstream.Close() }()
+
+	done := make(chan struct{}, 2)
+
+	pipe := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		// propagate EOF, the other direction may still have data
+		if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+			_ = cw.CloseWrite()
+		}
+
+		done <- struct{}{}
+	}
+
+	go pipe(upstream, client)
+	go pipe(client, upstream)
+
+	<-done
+	<-done
+}
+
+func closeAll(listeners map[string]net.Listener) {
+	for _, l := range listeners {
+		_ = l.Close()
+	}
+}
@@ -0,0 +1,110 @@
+package activation_test
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/activation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListeners_NotActivated(t *testing.T) {
+	// descriptors for another process
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+
+	l, err := activation.Listeners()
+	assert.NoError(t, err)
+	assert.Empty(t, l)
+	// cleared for the workers
+	assert.Empty(t, os.Getenv("LISTEN_FDS"))
+}
+
+func TestBridge_Overrides(t *testing.T) {
+	settings := map[string]any{
+		"http.address": "fd://http",
+		"rpc.listen":   "tcp://127.0.0.1:6001",
+		"http.pool":    map[string]any{"num_workers": 4},
+	}
+
+	var nb *activation.Bridge
+
+	_, err := nb.Overrides(settings)
+	assert.Error(t, err)
+
+	ov, err := nb.Overrides(map[string]any{"rpc.listen": "tcp://127.0.0.1:6001"})
+	assert.NoError(t, err)
+	assert.Empty(t, ov)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	b, err := activation.NewBridge(map[string]net.Listener{"http": l})
+	require.NoError(t, err)
+
+	defer b.Stop()
+
+	ov, err = b.Overrides(settings)
+	require.NoError(t, err)
+	require.Len(t, ov, 1)
+	assert.True(t, strings.HasPrefix(ov[0], "http.address=unix://"))
+	assert.True(t, strings.HasSuffix(ov[0], string(filepath.Separator)+"http.sock"))
+
+	_, err = b.Overrides(map[string]any{"grpc.listen": "fd://grpc"})
+	assert.Error(t, err)
+}
+
+func TestBridge_Forward(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	// activated unix sockets are bridged the same way
+	unix, err := net.Listen("unix", filepath.Join(t.TempDir(), "rr.sock"))
+	require.NoError(t, err)
+
+	b, err := activation.NewBridge(map[string]net.Listener{"tcp": tcp, "unix": unix})
+	require.NoError(t, err)
+
+	for _, name := range []string{"tcp", "unix"} {
+		addr, ok := b.Address(name)
+		require.True(t, ok)
+
+		// plugin side
+		upstream, errL := net.Listen("unix", strings.TrimPrefix(addr, "unix://"))
+		require.NoError(t, errL)
+
+		defer func() { _ = upstream.Close() }()
+
+		go func() {
+			conn, errA := upstream.Accept()
+			if errA != nil {
+				return
+			}
+
+			_, _ = io.Copy(conn, conn)
+			_ = conn.Close()
+		}()
+	}
+
+	b.Start()
+	defer b.Stop()
+
+	for _, l := range []net.Listener{tcp, unix} {
+		conn, errD := net.Dial(l.Addr().Network(), l.Addr().String())
+		require.NoError(t, errD)
+
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		require.NoError(t, conn.(interface{ CloseWrite() error }).CloseWrite())
+
+		data, errR := io.ReadAll(conn)
+		require.NoError(t, errR)
+		assert.Equal(t, "ping", string(data))
+	}
+}
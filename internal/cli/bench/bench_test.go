@@ -0,0 +1,240 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseEntry(t *testing.T) {
+	e, err := parseEntry("3:post /api/users {\"name\":\"a b\"}", false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, e.weight)
+	assert.Equal(t, "POST", e.method)
+	assert.Equal(t, "/api/users", e.path)
+	assert.Equal(t, `{"name":"a b"}`, string(e.body))
+
+	body := filepath.Join(t.TempDir(), "msg.bin")
+	require.NoError(t, os.WriteFile(body, []byte{0x0a, 0x01}, 0o600))
+
+	e, err = parseEntry("/pkg.Service/Method @"+body, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, e.weight)
+	assert.Equal(t, []byte{0x0a, 0x01}, e.body)
+
+	for _, tt := range []struct {
+		entry string
+		grpc  bool
+	}{
+		{"GET", false},
+		{"GET api", false},
+		{"0:GET /", false},
+		{"/Method", true},
+		{"GET / @missing.json", false},
+	} {
+		_, err = parseEntry(tt.entry, tt.grpc)
+		assert.Error(t, err, tt.entry)
+	}
+}
+
+func TestMix_Pick(t *testing.T) {
+	m, err := newMix([]string{"3:GET /a", "GET /b"}, false)
+	require.NoError(t, err)
+
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+	picked := map[string]int{}
+
+	for i := 0; i < 4000; i++ {
+		picked[m.pick(rnd).path]++
+	}
+
+	assert.InDelta(t, 3000, picked["/a"], 200)
+	assert.InDelta(t, 1000, picked["/b"], 200)
+}
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram()
+	for i := 100; i > 0; i-- {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+
+	p := h.quantiles(0.5, 0.99, 1)
+	assert.InEpsilon(t, 50*time.Millisecond, p[0], 0.01)
+	assert.InEpsilon(t, 99*time.Millisecond, p[1], 0.01)
+	assert.Equal(t, 100*time.Millisecond, p[2])
+	assert.Equal(t, 50500*time.Microsecond, h.mean())
+
+	assert.Equal(t, []time.Duration{0}, newHistogram().quantiles(0.5))
+
+	// every value is within its bucket range
+	for _, v := range []uint64{0, 1, 255, 256, 257, 1000, 123456789, 1 << 40, 1<<62 + 12345} {
+		b := bucket(v)
+		assert.Less(t, b, buckets)
+		assert.GreaterOrEqual(t, highest(b), v)
+		assert.LessOrEqual(t, float64(highest(b)-v), float64(v)/subBuckets, v)
+	}
+}
+
+func TestRunner_HTTP(t *testing.T) {
+	var served int64
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&served, 1)
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	m, err := newMix([]string{"GET /", "POST /fail body"}, false)
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	r := &runner{
+		target:      newHTTPTarget(srv.Listener.Addr().String(), http.Header{}, 4, time.Second),
+		mix:         m,
+		concurrency: 4,
+		requests:    200,
+		interval:    time.Millisecond * 10,
+		out:         out,
+	}
+
+	st := r.run(context.Background())
+
+	assert.EqualValues(t, 200, atomic.LoadInt64(&served))
+	assert.EqualValues(t, 200, st.latencies.count+st.failed.count)
+	assert.EqualValues(t, st.codes["500"], st.failed.count)
+	assert.Equal(t, 200, st.codes["200"]+st.codes["500"])
+	assert.Contains(t, out.String(), "requests: 200 in ")
+	assert.Contains(t, out.String(), "latency:  avg ")
+	assert.Contains(t, out.String(), "failed:   avg ")
+}
+
+func TestRunner_RateAndDuration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	m, err := newMix([]string{"GET /"}, false)
+	require.NoError(t, err)
+
+	r := &runner{
+		target:      newHTTPTarget("http://"+srv.Listener.Addr().String(), http.Header{}, 2, time.Second),
+		mix:         m,
+		concurrency: 2,
+		rate:        100,
+		duration:    time.Millisecond * 500,
+		interval:    time.Second,
+		out:         &bytes.Buffer{},
+	}
+
+	st := r.run(context.Background())
+	assert.InDelta(t, 50, st.latencies.count, 15)
+}
+
+func TestRunner_RateShortfall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		time.Sleep(time.Millisecond * 50)
+	}))
+	defer srv.Close()
+
+	m, err := newMix([]string{"GET /"}, false)
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	r := &runner{
+		target:      newHTTPTarget("http://"+srv.Listener.Addr().String(), http.Header{}, 1, time.Second),
+		mix:         m,
+		concurrency: 1,
+		rate:        100,
+		requests:    10,
+		interval:    time.Second,
+		out:         out,
+	}
+
+	st := r.run(context.Background())
+	require.EqualValues(t, 10, st.latencies.count)
+
+	// requests scheduled every 10ms wait for the only client, the wait is counted in the latency
+	assert.Greater(t, st.latencies.max, time.Millisecond*300)
+	assert.Contains(t, out.String(), "req/s requested, the clients can't keep up")
+}
+
+func TestRunner_GRPC(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		if method == "/test.Service/Fail" {
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+
+		var in []byte
+		if err := stream.RecvMsg(&in); err != nil {
+			return err
+		}
+
+		return stream.SendMsg(&in)
+	}), grpc.ForceServerCodec(rawCodec{}))
+
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	target, err := newGRPCTarget("tcp://"+l.Addr().String(), time.Second)
+	require.NoError(t, err)
+
+	defer target.close()
+
+	m, err := newMix([]string{"/test.Service/Echo", "/test.Service/Fail"}, true)
+	require.NoError(t, err)
+
+	r := &runner{target: target, mix: m, concurrency: 2, requests: 50, interval: time.Second, out: &bytes.Buffer{}}
+
+	st := r.run(context.Background())
+	assert.Equal(t, 50, st.codes["OK"]+st.codes["Unavailable"])
+	assert.EqualValues(t, st.codes["Unavailable"], st.failed.count)
+}
+
+func TestResolveTarget(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte(`
+version: "2.7"
+http:
+  address: 127.0.0.1:8080
+grpc:
+  listen: tcp://127.0.0.1:9001
+`), 0o600))
+
+	target, address, err := resolveTarget(&cfg, nil, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, targetHTTP, target)
+	assert.Equal(t, "127.0.0.1:8080", address)
+
+	target, address, err = resolveTarget(&cfg, nil, targetGRPC, "")
+	require.NoError(t, err)
+	assert.Equal(t, targetGRPC, target)
+	assert.Equal(t, "tcp://127.0.0.1:9001", address)
+
+	_, _, err = resolveTarget(&cfg, []string{"http.address=127.0.0.1:8081"}, "tcp", "")
+	assert.Error(t, err)
+
+	target, address, err = resolveTarget(nil, nil, "", "127.0.0.1:80")
+	require.NoError(t, err)
+	assert.Equal(t, targetHTTP, target)
+	assert.Equal(t, "127.0.0.1:80", address)
+}
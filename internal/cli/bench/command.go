@@ -0,0 +1,196 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+	internalRpc "github.com/roadrunner-server/roadrunner/v2/internal/rpc"
+
+	configImpl "github.com/roadrunner-server/config/v2"
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	targetHTTP string = "http"
+	targetGRPC string = "grpc"
+
+	// requests per second with the send interval of 1ns
+	maxRate float64 = 1e9
+)
+
+// NewCommand creates `bench` command.
+func NewCommand(cfgFile *string, override *[]string) *cobra.Command { //nolint:funlen
+	var (
+		target      string
+		address     string
+		requests    []string
+		header      []string
+		concurrency int
+		rate        float64
+		duration    time.Duration
+		total       int64
+		timeout     time.Duration
+		interval    time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Load test the configured HTTP or gRPC endpoint and watch the workers pool",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			const op = errors.Op("rr_bench")
+
+			if concurrency <= 0 {
+				return errors.E(op, errors.Str("concurrency should be positive"))
+			}
+
+			// the send interval is 1ns at most
+			if rate < 0 || rate > maxRate {
+				return errors.E(op, fmt.Errorf("rate should be between 0 and %.0f", maxRate))
+			}
+
+			// requests limit without the explicit duration runs until all requests are sent
+			if total > 0 && !cmd.Flags().Changed("duration") {
+				duration = 0
+			}
+
+			var err error
+			if target, address, err = resolveTarget(cfgFile, *override, target, address); err != nil {
+				return errors.E(op, err)
+			}
+
+			if len(requests) == 0 && target == targetHTTP {
+				requests = []string{"GET /"}
+			}
+
+			m, err := newMix(requests, target == targetGRPC)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			r := &runner{
+				mix:         m,
+				concurrency: concurrency,
+				rate:        rate,
+				duration:    duration,
+				requests:    total,
+				interval:    interval,
+				out:         cmd.OutOrStdout(),
+			}
+
+			switch target {
+			case targetHTTP:
+				h := http.Header{}
+				for _, v := range header {
+					k, val, ok := strings.Cut(v, ":")
+					if !ok {
+						return errors.E(op, fmt.Errorf("invalid header `%s` (Name: value)", v))
+					}
+
+					h.Add(strings.TrimSpace(k), strings.TrimSpace(val))
+				}
+
+				r.target = newHTTPTarget(address, h, concurrency, timeout)
+			case targetGRPC:
+				if r.target, err = newGRPCTarget(address, timeout); err != nil {
+					return errors.E(op, err)
+				}
+			}
+
+			defer r.target.close()
+
+			if cfgFile != nil {
+				client, errC := internalRpc.NewClient(*cfgFile, *override)
+				if errC == nil {
+					defer func() { _ = client.Close() }()
+
+					r.workers = newPoolWatcher(client, target)
+				} else {
+					_, _ = fmt.Fprintf(r.out, "workers are not watched: %v\n", errC)
+				}
+			}
+
+			_, _ = fmt.Fprintf(r.out, "benchmarking %s %s: %d clients, mix: %s\n", target, address, concurrency, mixString(m))
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			r.run(ctx)
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&target, "target", "", "plugin to benchmark: http or grpc (default: http when configured)")
+	f.StringVar(&address, "address", "", "target address instead of http.address or grpc.listen")
+	f.StringArrayVarP(&requests, "request", "r", nil,
+		"request of the mix: [weight:]METHOD /path [body|@file] or [weight:]/package.Service/Method [@file]")
+	f.StringArrayVarP(&header, "header", "H", nil, "HTTP request header (Name: value)")
+	f.IntVar(&concurrency, "concurrency", 10, "number of concurrent clients")
+	f.Float64Var(&rate, "rate", 0, "requests per second of all clients (0 unlimited)")
+	f.DurationVar(&duration, "duration", time.Second*10, "duration of the run (0 unlimited)")
+	f.Int64VarP(&total, "requests", "n", 0, "number of requests to send (0 unlimited)")
+	f.DurationVar(&timeout, "timeout", time.Second*10, "request timeout")
+	f.DurationVar(&interval, "interval", time.Second, "progress and workers polling interval")
+
+	return cmd
+}
+
+// resolveTarget picks the target plugin and its address from the configuration unless they are set explicitly.
+func resolveTarget(cfgFile *string, override []string, target, address string) (string, string, error) {
+	if target != "" && target != targetHTTP && target != targetGRPC {
+		return "", "", fmt.Errorf("unknown target `%s` (allowed: %s, %s)", target, targetHTTP, targetGRPC)
+	}
+
+	if address != "" {
+		if target == "" {
+			target = targetHTTP
+		}
+
+		return target, address, nil
+	}
+
+	if cfgFile == nil {
+		return "", "", errors.Str("no configuration file provided")
+	}
+
+	cfg := &configImpl.Plugin{Path: *cfgFile, Prefix: "rr", Flags: override, Version: meta.Version()}
+	if err := cfg.Init(); err != nil {
+		return "", "", err
+	}
+
+	keys := map[string]string{targetHTTP: "http.address", targetGRPC: "grpc.listen"}
+
+	for _, t := range []string{targetHTTP, targetGRPC} {
+		if target != "" && target != t {
+			continue
+		}
+
+		if addr, _ := cfg.Get(keys[t]).(string); addr != "" {
+			return t, addr, nil
+		}
+	}
+
+	if target != "" {
+		return "", "", fmt.Errorf("%s is not configured", keys[target])
+	}
+
+	return "", "", errors.Str("neither http.address nor grpc.listen is configured, use --address")
+}
+
+func mixString(m *mix) string {
+	parts := make([]string, 0, len(m.entries))
+	for _, e := range m.entries {
+		parts = append(parts, fmt.Sprintf("%d x %s", e.weight, e))
+	}
+
+	return strings.Join(parts, ", ")
+}
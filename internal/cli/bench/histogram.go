@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// values below 2^(subBits+1) ns are counted exactly, larger ones with the relative error below 2^-subBits (0.8%)
+	subBits    = 7
+	subBuckets = 1 << subBits
+	exact      = subBuckets << 1
+	buckets    = exact + (64-subBits-1)*subBuckets
+)
+
+// histogram counts latencies in the fixed number of log-linear buckets (as HDR histograms do), so the memory does not
+// grow with the number of requests.
+type histogram struct {
+	counts [buckets]uint64
+	count  uint64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+func newHistogram() *histogram {
+	return &histogram{min: math.MaxInt64}
+}
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	h.counts[bucket(uint64(d))]++
+	h.count++
+	h.sum += d
+
+	if d < h.min {
+		h.min = d
+	}
+
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// mean returns the average latency.
+func (h *histogram) mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	return h.sum / time.Duration(h.count)
+}
+
+// quantiles returns latencies at the quantiles: the highest value of the bucket, limited by the min and max latencies.
+func (h *histogram) quantiles(qs ...float64) []time.Duration {
+	out := make([]time.Duration, len(qs))
+	if h.count == 0 {
+		return out
+	}
+
+	for i, q := range qs {
+		rank := uint64(q*float64(h.count) + 0.5)
+		if rank == 0 {
+			rank = 1
+		}
+
+		var seen uint64
+
+		for b, c := range h.counts {
+			seen += c
+			if seen >= rank {
+				out[i] = time.Duration(highest(b))
+
+				break
+			}
+		}
+
+		if out[i] > h.max {
+			out[i] = h.max
+		}
+
+		if out[i] < h.min {
+			out[i] = h.min
+		}
+	}
+
+	return out
+}
+
+// bucket returns the bucket index of the value.
+func bucket(v uint64) int {
+	if v < exact {
+		return int(v)
+	}
+
+	// the value is v>>shift (subBits+1 significant bits) scaled by 2^shift
+	shift := bits.Len64(v) - subBits - 1
+
+	return exact + (shift-1)*subBuckets + int(v>>shift) - subBuckets
+}
+
+// highest returns the highest value counted in the bucket.
+func highest(b int) uint64 {
+	if b < exact {
+		return uint64(b)
+	}
+
+	shift := (b-exact)/subBuckets + 1
+	sub := uint64((b-exact)%subBuckets + subBuckets)
+
+	return (sub+1)<<shift - 1
+}
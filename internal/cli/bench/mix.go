@@ -0,0 +1,124 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// entry is a single request of the mix.
+type entry struct {
+	weight int
+	// HTTP method, empty for gRPC
+	method string
+	// HTTP path or gRPC method (/package.Service/Method)
+	path string
+	body []byte
+}
+
+// String returns the entry in the mix format.
+func (e *entry) String() string {
+	if e.method == "" {
+		return e.path
+	}
+
+	return e.method + " " + e.path
+}
+
+// parseEntry parses `[weight:]METHOD /path [body|@file]` (HTTP) or `[weight:]/package.Service/Method [@file]` (gRPC,
+// the file contains the encoded protobuf message).
+func parseEntry(s string, grpc bool) (*entry, error) {
+	e := &entry{weight: 1}
+
+	if w, rest, ok := strings.Cut(s, ":"); ok {
+		if n, err := strconv.Atoi(w); err == nil {
+			if n <= 0 {
+				return nil, fmt.Errorf("request `%s`: weight should be positive", s)
+			}
+
+			e.weight, s = n, rest
+		}
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(s), " ", 3)
+	if !grpc {
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("request `%s`: expected `METHOD /path [body|@file]`", s)
+		}
+
+		e.method, fields = strings.ToUpper(fields[0]), fields[1:]
+	}
+
+	e.path = fields[0]
+	if !strings.HasPrefix(e.path, "/") {
+		return nil, fmt.Errorf("request `%s`: path should start with /", s)
+	}
+
+	if grpc && strings.Count(e.path, "/") != 2 {
+		return nil, fmt.Errorf("request `%s`: expected `/package.Service/Method [@file]`", s)
+	}
+
+	if len(fields) > 1 {
+		body := strings.Join(fields[1:], " ")
+
+		if !strings.HasPrefix(body, "@") {
+			e.body = []byte(body)
+
+			return e, nil
+		}
+
+		data, err := os.ReadFile(body[1:])
+		if err != nil {
+			return nil, fmt.Errorf("request `%s`: %w", s, err)
+		}
+
+		e.body = data
+	}
+
+	return e, nil
+}
+
+// mix picks requests proportionally to their weights.
+type mix struct {
+	entries []*entry
+	total   int
+}
+
+func newMix(requests []string, grpc bool) (*mix, error) {
+	m := &mix{entries: make([]*entry, 0, len(requests))}
+
+	for _, r := range requests {
+		e, err := parseEntry(r, grpc)
+		if err != nil {
+			return nil, err
+		}
+
+		m.entries = append(m.entries, e)
+		m.total += e.weight
+	}
+
+	if len(m.entries) == 0 {
+		return nil, fmt.Errorf("no requests, use --request")
+	}
+
+	return m, nil
+}
+
+func (m *mix) pick(rnd *rand.Rand) *entry {
+	if len(m.entries) == 1 {
+		return m.entries[0]
+	}
+
+	n := rnd.Intn(m.total)
+	for _, e := range m.entries {
+		if n < e.weight {
+			return e
+		}
+
+		n -= e.weight
+	}
+
+	return m.entries[len(m.entries)-1]
+}
@@ -0,0 +1,186 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runner drives the load and reports the progress.
+type runner struct {
+	target      requester
+	mix         *mix
+	concurrency int
+	// requests per second of all clients, 0 is unlimited
+	rate float64
+	// duration and requests limit the run, 0 is unlimited
+	duration time.Duration
+	requests int64
+	interval time.Duration
+	// nil when the rpc plugin is not available
+	workers *poolWatcher
+	out     io.Writer
+}
+
+func (r *runner) run(ctx context.Context) *stats {
+	if r.duration > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, r.duration)
+		defer cancel()
+	}
+
+	st := newStats()
+	tokens := r.limiter(ctx)
+
+	var (
+		wg   sync.WaitGroup
+		sent int64
+	)
+
+	start := time.Now()
+
+	for i := 0; i < r.concurrency; i++ {
+		wg.Add(1)
+
+		go func(seed int64) {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(seed)) //nolint:gosec
+
+			for {
+				// with the rate the latency is measured from the scheduled send time, so the time the request
+				// waited for a free client is counted as well (no coordinated omission)
+				t := time.Now()
+				if tokens != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case t = <-tokens:
+					}
+				}
+
+				if ctx.Err() != nil || (r.requests > 0 && atomic.AddInt64(&sent, 1) > r.requests) {
+					return
+				}
+
+				e := r.mix.pick(rnd)
+
+				code, ok, err := r.target.do(ctx, e)
+				if err != nil && ctx.Err() != nil {
+					// canceled by the end of the run
+					return
+				}
+
+				st.add(time.Since(t), code, ok, err)
+			}
+		}(start.UnixNano() + int64(i))
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	r.progress(st, start, done)
+
+	elapsed := time.Since(start)
+	st.report(r.out, elapsed)
+
+	if r.rate > 0 {
+		if achieved := st.rate(elapsed); achieved < r.rate*0.99 {
+			_, _ = fmt.Fprintf(r.out, "rate:     %.1f req/s of %.1f req/s requested, the clients can't keep up "+
+				"(raise --concurrency), latencies include the wait\n", achieved, r.rate)
+		}
+	}
+
+	if r.workers != nil {
+		r.workers.report(r.out)
+	}
+
+	return st
+}
+
+// limiter returns the channel issuing the scheduled send times at the rate, nil when the rate is unlimited. No send
+// is dropped: the times scheduled while all clients are busy are issued as soon as a client is free.
+func (r *runner) limiter(ctx context.Context) <-chan time.Time {
+	if r.rate <= 0 {
+		return nil
+	}
+
+	tokens := make(chan time.Time)
+
+	go func() {
+		interval := time.Duration(float64(time.Second) / r.rate)
+		next := time.Now()
+
+		t := time.NewTimer(0)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+
+			select {
+			case tokens <- next:
+			case <-ctx.Done():
+				return
+			}
+
+			next = next.Add(interval)
+			t.Reset(time.Until(next))
+		}
+	}()
+
+	return tokens
+}
+
+// progress prints the interval rate and latency along with the workers state until the run is done.
+func (r *runner) progress(st *stats, start time.Time, done <-chan struct{}) {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	if r.workers != nil {
+		// initial state to compare with
+		if _, err := r.workers.poll(); err != nil {
+			r.workers = nil
+		}
+	}
+
+	prev := start
+
+	for {
+		select {
+		case <-done:
+			if r.workers != nil {
+				_, _ = r.workers.poll()
+			}
+
+			return
+		case now := <-t.C:
+			window := st.take()
+			p := window.quantiles(0.5, 0.99)
+
+			line := fmt.Sprintf("[%6s] %8.1f req/s, p50 %s, p99 %s", now.Sub(start).Round(time.Second),
+				float64(window.count)/now.Sub(prev).Seconds(), round(p[0]), round(p[1]))
+
+			if r.workers != nil {
+				if s, err := r.workers.poll(); err == nil {
+					line += " | " + s.String()
+				}
+			}
+
+			_, _ = fmt.Fprintln(r.out, line)
+			prev = now
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stats collects results of the requests.
+type stats struct {
+	mu sync.Mutex
+	// latencies of the successful requests
+	latencies *histogram
+	// latencies of the failed requests: transport errors and error codes
+	failed *histogram
+	// latencies since the last window call
+	window *histogram
+	codes  map[string]int
+	errors map[string]int
+}
+
+func newStats() *stats {
+	return &stats{
+		latencies: newHistogram(),
+		failed:    newHistogram(),
+		window:    newHistogram(),
+		codes:     make(map[string]int),
+		errors:    make(map[string]int),
+	}
+}
+
+func (s *stats) add(latency time.Duration, code string, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.window.record(latency)
+
+	if ok {
+		s.latencies.record(latency)
+	} else {
+		s.failed.record(latency)
+	}
+
+	if err != nil {
+		s.errors[err.Error()]++
+
+		return
+	}
+
+	s.codes[code]++
+}
+
+// take returns latencies since the previous call.
+func (s *stats) take() *histogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.window
+	s.window = newHistogram()
+
+	return w
+}
+
+// rate returns requests per second over the elapsed time.
+func (s *stats) rate(elapsed time.Duration) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return float64(s.latencies.count+s.failed.count) / elapsed.Seconds()
+}
+
+// report prints the summary of the run.
+func (s *stats) report(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := s.latencies.count + s.failed.count
+	_, _ = fmt.Fprintf(w, "\nrequests: %d in %s, %.1f req/s\n", total, elapsed.Round(time.Millisecond),
+		float64(total)/elapsed.Seconds())
+
+	if total == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "errors:   %d (%.2f%%)\n", s.failed.count, float64(s.failed.count)*100/float64(total))
+
+	if s.latencies.count > 0 {
+		_, _ = fmt.Fprintf(w, "latency:  %s\n", summary(s.latencies))
+	}
+
+	if s.failed.count > 0 {
+		_, _ = fmt.Fprintf(w, "failed:   %s\n", summary(s.failed))
+	}
+
+	_, _ = fmt.Fprintf(w, "codes:    %s\n", counts(s.codes))
+
+	if len(s.errors) > 0 {
+		_, _ = fmt.Fprintf(w, "transport errors:\n")
+
+		for _, e := range sortedKeys(s.errors) {
+			_, _ = fmt.Fprintf(w, "  %d x %s\n", s.errors[e], e)
+		}
+	}
+}
+
+// summary formats the average and percentile latencies.
+func summary(h *histogram) string {
+	p := h.quantiles(0.5, 0.9, 0.99)
+
+	return fmt.Sprintf("avg %s, p50 %s, p90 %s, p99 %s, max %s", round(h.mean()), round(p[0]), round(p[1]),
+		round(p[2]), round(h.max))
+}
+
+func counts(m map[string]int) string {
+	parts := make([]string, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		parts = append(parts, fmt.Sprintf("%s: %d", k, m[k]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d > time.Second:
+		return d.Round(time.Millisecond)
+	case d > time.Millisecond:
+		return d.Round(time.Microsecond * 10)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
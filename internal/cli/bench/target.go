@@ -0,0 +1,138 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// requester sends the request and returns the response code: HTTP status or gRPC code.
+type requester interface {
+	do(ctx context.Context, e *entry) (code string, ok bool, err error)
+	close()
+}
+
+type httpTarget struct {
+	base   string
+	header http.Header
+	client *http.Client
+}
+
+func newHTTPTarget(address string, header http.Header, concurrency int, timeout time.Duration) *httpTarget {
+	base := address
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+
+	return &httpTarget{
+		base:   strings.TrimSuffix(base, "/"),
+		header: header,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{MaxIdleConnsPerHost: concurrency, MaxConnsPerHost: concurrency},
+		},
+	}
+}
+
+func (t *httpTarget) do(ctx context.Context, e *entry) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, e.method, t.base+e.path, bytes.NewReader(e.body))
+	if err != nil {
+		return "", false, err
+	}
+
+	for k, v := range t.header {
+		req.Header[k] = v
+	}
+
+	rsp, err := t.client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+
+	_, _ = io.Copy(io.Discard, rsp.Body)
+	_ = rsp.Body.Close()
+
+	return strconv.Itoa(rsp.StatusCode), rsp.StatusCode < http.StatusBadRequest, nil
+}
+
+func (t *httpTarget) close() {
+	t.client.CloseIdleConnections()
+}
+
+type grpcTarget struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+func newGRPCTarget(address string, timeout time.Duration) (*grpcTarget, error) {
+	// grpc.listen is a DSN: tcp://127.0.0.1:9001
+	if _, addr, ok := strings.Cut(address, "://"); ok {
+		address = addr
+	}
+
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcTarget{conn: conn, timeout: timeout}, nil
+}
+
+func (t *grpcTarget) do(ctx context.Context, e *entry) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	in, out := e.body, []byte(nil)
+
+	err := t.conn.Invoke(ctx, e.path, &in, &out, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		st, ok := status.FromError(err)
+		if !ok {
+			return "", false, err
+		}
+
+		return st.Code().String(), false, nil
+	}
+
+	return "OK", true, nil
+}
+
+func (t *grpcTarget) close() {
+	_ = t.conn.Close()
+}
+
+// rawCodec passes already encoded protobuf messages.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+
+	*b = append((*b)[:0], data...)
+
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,99 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"net/rpc"
+
+	"github.com/dustin/go-humanize"
+	"github.com/roadrunner-server/informer/v2"
+)
+
+// informerWorkers is the informer RPC method, same as in the `workers` command.
+const informerWorkers string = "informer.Workers"
+
+// poolSample is the state of the plugin workers at the moment.
+type poolSample struct {
+	total  int
+	busy   int
+	memory uint64
+	execs  uint64
+}
+
+// poolWatcher polls the workers of the benchmarked plugin.
+type poolWatcher struct {
+	client *rpc.Client
+	plugin string
+
+	first, last *poolSample
+	maxBusy     int
+	maxMemory   uint64
+	// pids of all seen workers, more pids than workers means restarts
+	pids map[int]struct{}
+}
+
+func newPoolWatcher(client *rpc.Client, plugin string) *poolWatcher {
+	return &poolWatcher{client: client, plugin: plugin, pids: make(map[int]struct{})}
+}
+
+func (w *poolWatcher) poll() (*poolSample, error) {
+	list := &informer.WorkerList{}
+	if err := w.client.Call(informerWorkers, w.plugin, list); err != nil {
+		return nil, err
+	}
+
+	s := &poolSample{total: len(list.Workers)}
+
+	for _, st := range list.Workers {
+		if st.Status == "working" {
+			s.busy++
+		}
+
+		s.memory += st.MemoryUsage
+		s.execs += st.NumJobs
+		w.pids[st.Pid] = struct{}{}
+	}
+
+	if w.first == nil {
+		w.first = s
+	}
+
+	w.last = s
+
+	if s.busy > w.maxBusy {
+		w.maxBusy = s.busy
+	}
+
+	if s.memory > w.maxMemory {
+		w.maxMemory = s.memory
+	}
+
+	return s, nil
+}
+
+func (s *poolSample) String() string {
+	return fmt.Sprintf("workers %d/%d busy, mem %s, execs %d", s.busy, s.total, humanize.Bytes(s.memory), s.execs)
+}
+
+// report prints the pool behavior during the run.
+func (w *poolWatcher) report(out io.Writer) {
+	if w.first == nil {
+		return
+	}
+
+	saturation := 0.0
+	if w.last.total > 0 {
+		saturation = float64(w.maxBusy) * 100 / float64(w.last.total)
+	}
+
+	restarts := len(w.pids) - w.first.total
+	if restarts < 0 {
+		restarts = 0
+	}
+
+	_, _ = fmt.Fprintf(out, "workers:  %d, max busy %d (%.0f%% saturation), restarts %d\n",
+		w.last.total, w.maxBusy, saturation, restarts)
+	_, _ = fmt.Fprintf(out, "memory:   %s -> %s (max %s)\n",
+		humanize.Bytes(w.first.memory), humanize.Bytes(w.last.memory), humanize.Bytes(w.maxMemory))
+	_, _ = fmt.Fprintf(out, "execs:    %d -> %d\n", w.first.execs, w.last.execs)
+}
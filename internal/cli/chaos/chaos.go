@@ -0,0 +1,351 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roadrunner-server/api/v2/plugins/jobs"
+	"github.com/roadrunner-server/informer/v2"
+)
+
+const (
+	informerList    string = "informer.List"
+	informerWorkers string = "informer.Workers"
+	informerJobs    string = "informer.Jobs"
+	jobsPause       string = "jobs.Pause"
+	jobsResume      string = "jobs.Resume"
+	// service plugin processes are not pool workers
+	servicePluginName string = "service"
+	jobsPluginName    string = "jobs"
+)
+
+// actions
+const (
+	actionKill  string = "kill"
+	actionHang  string = "hang"
+	actionDelay string = "delay"
+	actionPause string = "pause"
+)
+
+// errNoTarget is returned when there is nothing to break, the step is skipped.
+var errNoTarget = errors.New("no target") //nolint:gochecknoglobals
+
+// caller is the RPC client.
+type caller interface {
+	Call(serviceMethod string, args interface{}, reply interface{}) error
+}
+
+// signaler sends signals to the worker processes.
+type signaler struct {
+	kill    func(pid int) error
+	stop    func(pid int) error
+	cont    func(pid int) error
+	delay   func(pid int) error
+	undelay func(pid int) error
+}
+
+// pipelines is the jobs.Pause/jobs.Resume request, decoded by the jobs plugin into its proto message.
+type pipelines struct {
+	Pipelines []string
+}
+
+// monkey breaks workers and pipelines, every broken worker and pipeline is recovered after the configured duration.
+type monkey struct {
+	client caller
+	sig    signaler
+	rnd    *rand.Rand
+	logf   func(format string, args ...interface{})
+
+	// plugins with workers, all informer plugins by default
+	plugins []string
+	// pipelines to pause, all ready pipelines by default
+	pipelines []string
+
+	hang  time.Duration
+	delay time.Duration
+	pause time.Duration
+
+	mu sync.Mutex
+	// recovery functions of the stopped workers and paused pipelines
+	pending map[string]*recovery
+}
+
+type recovery struct {
+	timer *time.Timer
+	fn    func()
+}
+
+// step performs the action, errNoTarget means there was nothing to break.
+func (m *monkey) step(action string) error {
+	switch action {
+	case actionKill:
+		plugin, pid, err := m.worker()
+		if err != nil {
+			return err
+		}
+
+		if err = m.sig.kill(pid); err != nil {
+			return fmt.Errorf("kill pid %d: %w", pid, err)
+		}
+
+		m.logf("kill: plugin=%s pid=%d", plugin, pid)
+
+		return nil
+	case actionHang:
+		plugin, pid, err := m.worker()
+		if err != nil {
+			return err
+		}
+
+		if err = m.sig.stop(pid); err != nil {
+			return fmt.Errorf("stop pid %d: %w", pid, err)
+		}
+
+		m.logf("hang: plugin=%s pid=%d for=%s", plugin, pid, m.hang)
+
+		m.schedule(fmt.Sprintf("pid:%d", pid), m.hang, func() {
+			if err := m.sig.cont(pid); err != nil {
+				m.logf("hang: resume pid=%d failed: %v", pid, err)
+
+				return
+			}
+
+			m.logf("hang: plugin=%s pid=%d resumed", plugin, pid)
+		})
+
+		return nil
+	case actionDelay:
+		// the worker runs behind the chaos relay, the relay holds the frames sent to the worker
+		plugin, pid, err := m.worker()
+		if err != nil {
+			return err
+		}
+
+		if err = m.sig.delay(pid); err != nil {
+			return fmt.Errorf("delay pid %d: %w", pid, err)
+		}
+
+		m.logf("delay: plugin=%s pid=%d for=%s", plugin, pid, m.delay)
+
+		m.schedule(fmt.Sprintf("pid:%d", pid), m.delay, func() {
+			if err := m.sig.undelay(pid); err != nil {
+				m.logf("delay: undelay pid=%d failed: %v", pid, err)
+
+				return
+			}
+
+			m.logf("delay: plugin=%s pid=%d undelayed", plugin, pid)
+		})
+
+		return nil
+	case actionPause:
+		pipeline, err := m.pipeline()
+		if err != nil {
+			return err
+		}
+
+		if err = m.client.Call(jobsPause, &pipelines{Pipelines: []string{pipeline}}, nil); err != nil {
+			return fmt.Errorf("pause pipeline %s: %w", pipeline, err)
+		}
+
+		m.logf("pause: pipeline=%s for=%s", pipeline, m.pause)
+
+		m.schedule("pipeline:"+pipeline, m.pause, func() {
+			if err := m.client.Call(jobsResume, &pipelines{Pipelines: []string{pipeline}}, nil); err != nil {
+				m.logf("pause: resume pipeline=%s failed: %v", pipeline, err)
+
+				return
+			}
+
+			m.logf("pause: pipeline=%s resumed", pipeline)
+		})
+
+		return nil
+	default:
+		return fmt.Errorf("unknown action `%s`", action)
+	}
+}
+
+// worker picks a random worker which is not stopped already.
+func (m *monkey) worker() (string, int, error) {
+	plugins := m.plugins
+	if len(plugins) == 0 {
+		if err := m.client.Call(informerList, true, &plugins); err != nil {
+			return "", 0, err
+		}
+	}
+
+	type target struct {
+		plugin string
+		pid    int
+	}
+
+	var targets []target
+
+	for _, plugin := range plugins {
+		if plugin == servicePluginName && len(m.plugins) == 0 {
+			continue
+		}
+
+		list := &informer.WorkerList{}
+		if err := m.client.Call(informerWorkers, plugin, list); err != nil {
+			return "", 0, err
+		}
+
+		for _, w := range list.Workers {
+			if !m.isPending(fmt.Sprintf("pid:%d", w.Pid)) {
+				targets = append(targets, target{plugin: plugin, pid: w.Pid})
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return "", 0, errNoTarget
+	}
+
+	t := targets[m.rnd.Intn(len(targets))]
+
+	return t.plugin, t.pid, nil
+}
+
+// pipeline picks a random pipeline which is not paused already.
+func (m *monkey) pipeline() (string, error) {
+	names := m.pipelines
+	if len(names) == 0 {
+		var states []*jobs.State
+		if err := m.client.Call(informerJobs, jobsPluginName, &states); err != nil {
+			return "", err
+		}
+
+		for _, st := range states {
+			if st.Ready {
+				names = append(names, st.Pipeline)
+			}
+		}
+
+		sort.Strings(names)
+	}
+
+	var free []string
+
+	for _, name := range names {
+		if !m.isPending("pipeline:" + name) {
+			free = append(free, name)
+		}
+	}
+
+	if len(free) == 0 {
+		return "", errNoTarget
+	}
+
+	return free[m.rnd.Intn(len(free))], nil
+}
+
+// schedule runs the recovery after the duration.
+func (m *monkey) schedule(key string, d time.Duration, fn func()) {
+	r := &recovery{fn: fn}
+
+	m.mu.Lock()
+	m.pending[key] = r
+	r.timer = time.AfterFunc(d, func() {
+		m.mu.Lock()
+		_, ok := m.pending[key]
+		delete(m.pending, key)
+		m.mu.Unlock()
+
+		// recovered already by the restore
+		if ok {
+			fn()
+		}
+	})
+	m.mu.Unlock()
+}
+
+func (m *monkey) isPending(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.pending[key]
+
+	return ok
+}
+
+// restore immediately recovers all stopped workers and paused pipelines.
+func (m *monkey) restore() {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = make(map[string]*recovery)
+	m.mu.Unlock()
+
+	// the timer callback skips keys removed from the pending
+	for _, r := range pending {
+		r.timer.Stop()
+		r.fn()
+	}
+}
+
+// run injects faults until the context is done or the faults limit is reached, recovers everything on exit and
+// returns the number of injected faults.
+func (m *monkey) run(ctx context.Context, actions []string, interval time.Duration, random bool, limit int) int {
+	defer m.restore()
+
+	faults := 0
+
+	for {
+		action := actions[m.rnd.Intn(len(actions))]
+
+		switch err := m.step(action); {
+		case err == nil:
+			faults++
+		case errors.Is(err, errNoTarget):
+			m.logf("%s: skipped, nothing to break", action)
+		default:
+			m.logf("%s: failed: %v", action, err)
+		}
+
+		if limit > 0 && faults >= limit {
+			// let the last fault recover on schedule
+			m.wait(ctx)
+
+			return faults
+		}
+
+		d := interval
+		if random {
+			d = time.Duration(m.rnd.Int63n(int64(interval) * 2))
+		}
+
+		select {
+		case <-ctx.Done():
+			return faults
+		case <-time.After(d):
+		}
+	}
+}
+
+// wait waits until all faults are recovered or the context is done.
+func (m *monkey) wait(ctx context.Context) {
+	t := time.NewTicker(time.Millisecond * 100)
+	defer t.Stop()
+
+	for {
+		m.mu.Lock()
+		n := len(m.pending)
+		m.mu.Unlock()
+
+		if n == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/api/v2/plugins/jobs"
+	"github.com/roadrunner-server/api/v2/state/process"
+	"github.com/roadrunner-server/informer/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fake is the RoadRunner RPC and the worker processes.
+type fake struct {
+	mu      sync.Mutex
+	workers map[string][]int
+	jobs    []*jobs.State
+	// signals and RPC calls in order
+	events []string
+}
+
+func (f *fake) Call(method string, args interface{}, reply interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch method {
+	case informerList:
+		*reply.(*[]string) = []string{"http", "service"}
+	case informerWorkers:
+		list := reply.(*informer.WorkerList)
+		for _, pid := range f.workers[args.(string)] {
+			list.Workers = append(list.Workers, &process.State{Pid: pid})
+		}
+	case informerJobs:
+		*reply.(*[]*jobs.State) = f.jobs
+	case jobsPause, jobsResume:
+		f.events = append(f.events, fmt.Sprintf("%s %v", method, args.(*pipelines).Pipelines))
+	default:
+		return fmt.Errorf("unknown method %s", method)
+	}
+
+	return nil
+}
+
+func (f *fake) signal(name string) func(int) error {
+	return func(pid int) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.events = append(f.events, fmt.Sprintf("%s %d", name, pid))
+
+		return nil
+	}
+}
+
+func (f *fake) log() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.events...)
+}
+
+func newMonkey(f *fake) *monkey {
+	return &monkey{
+		client: f,
+		sig: signaler{
+			kill:    f.signal("kill"),
+			stop:    f.signal("stop"),
+			cont:    f.signal("cont"),
+			delay:   f.signal("delay"),
+			undelay: f.signal("undelay"),
+		},
+		rnd:     rand.New(rand.NewSource(1)), //nolint:gosec
+		logf:    func(string, ...interface{}) {},
+		hang:    time.Hour,
+		delay:   time.Millisecond * 10,
+		pause:   time.Hour,
+		pending: make(map[string]*recovery),
+	}
+}
+
+func TestMonkey_Kill(t *testing.T) {
+	f := &fake{workers: map[string][]int{"http": {10}, "service": {20}}}
+	m := newMonkey(f)
+
+	// service processes are skipped unless requested
+	require.NoError(t, m.step(actionKill))
+	assert.Equal(t, []string{"kill 10"}, f.log())
+
+	m.plugins = []string{"service"}
+	require.NoError(t, m.step(actionKill))
+	assert.Equal(t, []string{"kill 10", "kill 20"}, f.log())
+}
+
+func TestMonkey_Hang(t *testing.T) {
+	f := &fake{workers: map[string][]int{"http": {10}}}
+	m := newMonkey(f)
+
+	require.NoError(t, m.step(actionHang))
+	assert.Equal(t, []string{"stop 10"}, f.log())
+
+	// the only worker is stopped already
+	assert.ErrorIs(t, m.step(actionHang), errNoTarget)
+
+	m.restore()
+	assert.Equal(t, []string{"stop 10", "cont 10"}, f.log())
+}
+
+func TestMonkey_Delay(t *testing.T) {
+	f := &fake{workers: map[string][]int{"http": {10}}}
+	m := newMonkey(f)
+
+	require.NoError(t, m.step(actionDelay))
+
+	assert.Eventually(t, func() bool { return len(f.log()) == 2 }, time.Second, time.Millisecond*10)
+	assert.Equal(t, []string{"delay 10", "undelay 10"}, f.log())
+
+	// recovered workers are not resumed again
+	m.restore()
+	assert.Len(t, f.log(), 2)
+}
+
+func TestMonkey_Pause(t *testing.T) {
+	f := &fake{jobs: []*jobs.State{{Pipeline: "emails", Ready: true}, {Pipeline: "paused"}}}
+	m := newMonkey(f)
+
+	require.NoError(t, m.step(actionPause))
+	assert.ErrorIs(t, m.step(actionPause), errNoTarget)
+
+	m.restore()
+	assert.Equal(t, []string{"jobs.Pause [emails]", "jobs.Resume [emails]"}, f.log())
+}
+
+func TestMonkey_Run(t *testing.T) {
+	f := &fake{workers: map[string][]int{"http": {10, 11, 12}}}
+	m := newMonkey(f)
+	m.delay = time.Millisecond
+
+	faults := m.run(context.Background(), []string{actionKill, actionDelay}, time.Millisecond, true, 5)
+	assert.Equal(t, 5, faults)
+
+	// every delayed worker is undelayed
+	delays, undelays := 0, 0
+	for _, e := range f.log() {
+		switch strings.Fields(e)[0] {
+		case "delay":
+			delays++
+		case "undelay":
+			undelays++
+		}
+	}
+
+	assert.NotZero(t, delays)
+	assert.Equal(t, delays, undelays)
+}
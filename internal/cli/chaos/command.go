@@ -0,0 +1,129 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	internalRpc "github.com/roadrunner-server/roadrunner/v2/internal/rpc"
+
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand creates `chaos` command.
+func NewCommand(cfgFile *string, override *[]string) *cobra.Command { //nolint:funlen
+	var (
+		actions   []string
+		pipes     []string
+		interval  time.Duration
+		random    bool
+		duration  time.Duration
+		hang      time.Duration
+		delay     time.Duration
+		pause     time.Duration
+		seed      int64
+		maxFaults int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "chaos [plugin...]",
+		Short: "Break workers and jobs pipelines of the local RoadRunner to test its resilience",
+		Long: `Break workers of the plugins (all by default) and jobs pipelines on a schedule:
+
+  kill   SIGKILL a random worker, the pool should replace it
+  hang   SIGSTOP a random worker for --hang, then SIGCONT (supervisor and allocate_timeout)
+  delay  delay the relay of a random worker for --delay (latency spikes), the workers must run behind
+         the chaos relay (server.command: rr chaos relay --latency 200ms -- php worker.php)
+  pause  pause a random jobs pipeline for --pause, then resume it
+
+Workers are signaled by PIDs, so RoadRunner must run on the same host. The delay action signals the relay
+(SIGUSR1, SIGUSR2), which terminates workers started without it. Stopped workers, delayed relays and paused
+pipelines are recovered on exit.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			const op = errors.Op("rr_chaos")
+
+			for _, a := range actions {
+				switch a {
+				case actionKill, actionHang, actionDelay, actionPause:
+				default:
+					return errors.E(op, fmt.Errorf("unknown action `%s` (allowed: kill, hang, delay, pause)", a))
+				}
+			}
+
+			if len(actions) == 0 || interval <= 0 {
+				return errors.E(op, errors.Str("at least one action and a positive interval are required"))
+			}
+
+			if cfgFile == nil {
+				return errors.E(op, errors.Str("no configuration file provided"))
+			}
+
+			client, err := internalRpc.NewClient(*cfgFile, *override)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			defer func() { _ = client.Close() }()
+
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+
+			logger := log.New(cmd.OutOrStdout(), "", log.LstdFlags|log.Lmicroseconds)
+
+			m := &monkey{
+				client:    client,
+				sig:       processSignaler(),
+				rnd:       rand.New(rand.NewSource(seed)), //nolint:gosec
+				logf:      logger.Printf,
+				plugins:   args,
+				pipelines: pipes,
+				hang:      hang,
+				delay:     delay,
+				pause:     pause,
+				pending:   make(map[string]*recovery),
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if duration > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, duration)
+
+				defer cancel()
+			}
+
+			m.logf("chaos: actions=%v interval=%s random=%t seed=%d", actions, interval, random, seed)
+
+			faults := m.run(ctx, actions, interval, random, maxFaults)
+
+			m.logf("chaos: done, %d faults injected", faults)
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVarP(&actions, "action", "a", []string{actionKill, actionHang},
+		"actions to choose from: kill, hang, delay, pause")
+	f.StringArrayVar(&pipes, "pipeline", nil, "jobs pipeline to pause (default: all ready pipelines)")
+	f.DurationVarP(&interval, "interval", "i", time.Second*5, "interval between the actions")
+	f.BoolVar(&random, "random", false, "random intervals between the actions (0-2x of the interval)")
+	f.DurationVar(&duration, "duration", 0, "duration of the run (0 until interrupted)")
+	f.IntVarP(&maxFaults, "faults", "n", 0, "number of faults to inject (0 unlimited)")
+	f.DurationVar(&hang, "hang", time.Second*30, "how long the hung worker stays stopped")
+	f.DurationVar(&delay, "delay", time.Second*30, "how long the relay of the delayed worker stays slow")
+	f.DurationVar(&pause, "pause", time.Second*30, "how long the pipeline stays paused")
+	f.Int64Var(&seed, "seed", 0, "random seed to repeat the run (0 random)")
+
+	cmd.AddCommand(newRelayCommand())
+
+	return cmd
+}
@@ -0,0 +1,140 @@
+package chaos
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/roadrunner-server/errors"
+	"github.com/roadrunner-server/goridge/v3/pkg/frame"
+	"github.com/roadrunner-server/goridge/v3/pkg/pipe"
+	"github.com/spf13/cobra"
+)
+
+// newRelayCommand creates `chaos relay` command.
+func newRelayCommand() *cobra.Command {
+	r := &relay{}
+
+	cmd := &cobra.Command{
+		Use:   "relay -- command [args...]",
+		Short: "Run the worker behind the relay delayed by the chaos delay action (server.command, pipes relay only)",
+		Long: `Run the worker command and forward the goridge frames between RoadRunner and the worker (pipes relay).
+While the chaos delay action is on, every frame sent to the worker is held for --latency.
+
+The delay action signals the relay process (SIGUSR1 on, SIGUSR2 off), a worker started without the relay is
+terminated by these signals.`,
+		Example: `  server:
+    command: "rr chaos relay --latency 200ms -- php worker.php"`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			const op = errors.Op("rr_chaos_relay")
+
+			if err := r.run(args, os.Stdin, os.Stdout); err != nil {
+				return errors.E(op, err)
+			}
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	// flags after the command belong to it
+	f.SetInterspersed(false)
+	f.DurationVar(&r.latency, "latency", time.Millisecond*500, "delay of every frame sent to the worker while delayed")
+
+	return cmd
+}
+
+// relay forwards the goridge frames between RoadRunner and the worker, frames sent to the worker are held for the
+// latency while the relay is delayed.
+type relay struct {
+	latency time.Duration
+	delayed int32
+}
+
+// run starts the worker and forwards the frames until the worker exits.
+func (r *relay) run(args []string, stdin io.ReadCloser, stdout io.WriteCloser) error {
+	on, off := delaySignals()
+	if on == nil {
+		return errors.Str("delaying the relay is not supported on windows")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec
+	cmd.Stderr = os.Stderr
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, on, off, os.Interrupt, syscall.SIGTERM)
+
+	defer signal.Stop(signals)
+
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	go func() {
+		for sig := range signals {
+			switch sig {
+			case on:
+				r.delay(true)
+			case off:
+				r.delay(false)
+			default:
+				_ = cmd.Process.Signal(sig)
+			}
+		}
+	}()
+
+	go func() {
+		// the worker gets EOF when RoadRunner closes the relay
+		_ = r.forward(stdin, in, true)
+		_ = in.Close()
+	}()
+
+	_ = r.forward(out, stdout, false)
+
+	return cmd.Wait()
+}
+
+// forward copies the frames until the source is closed, the frames are held for the latency if delayable.
+func (r *relay) forward(src io.ReadCloser, dst io.WriteCloser, delayable bool) error {
+	from, to := pipe.NewPipeRelay(src, nil), pipe.NewPipeRelay(nil, dst)
+
+	for {
+		fr := frame.NewFrame()
+		if err := from.Receive(fr); err != nil {
+			return err
+		}
+
+		if delayable && atomic.LoadInt32(&r.delayed) == 1 {
+			time.Sleep(r.latency)
+		}
+
+		if err := to.Send(fr); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *relay) delay(on bool) {
+	if on {
+		atomic.StoreInt32(&r.delayed, 1)
+
+		return
+	}
+
+	atomic.StoreInt32(&r.delayed, 0)
+}
@@ -0,0 +1,50 @@
+package chaos
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/goridge/v3/pkg/frame"
+	"github.com/roadrunner-server/goridge/v3/pkg/pipe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelay_Forward(t *testing.T) {
+	r := &relay{latency: time.Millisecond * 200}
+
+	srcR, srcW := io.Pipe()
+	dstR, dstW := io.Pipe()
+
+	go func() { _ = r.forward(srcR, dstW, true) }()
+
+	send, recv := pipe.NewPipeRelay(nil, srcW), pipe.NewPipeRelay(dstR, nil)
+
+	roundtrip := func(payload string) time.Duration {
+		fr := frame.NewFrame()
+		fr.WriteVersion(fr.Header(), frame.Version1)
+		fr.WritePayloadLen(fr.Header(), uint32(len(payload)))
+		fr.WritePayload([]byte(payload))
+		fr.WriteCRC(fr.Header())
+
+		start := time.Now()
+		require.NoError(t, send.Send(fr))
+
+		got := frame.NewFrame()
+		require.NoError(t, recv.Receive(got))
+		assert.Equal(t, payload, string(got.Payload()))
+
+		return time.Since(start)
+	}
+
+	assert.Less(t, roundtrip("fast"), r.latency)
+
+	r.delay(true)
+	assert.GreaterOrEqual(t, roundtrip("slow"), r.latency)
+
+	r.delay(false)
+	assert.Less(t, roundtrip("fast again"), r.latency)
+
+	_ = srcW.Close()
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package chaos
+
+import (
+	"os"
+	"syscall"
+)
+
+// processSignaler kills, stops (SIGSTOP) and continues (SIGCONT) the worker processes, delays and undelays the relays.
+func processSignaler() signaler {
+	return signaler{
+		kill:    func(pid int) error { return syscall.Kill(pid, syscall.SIGKILL) },
+		stop:    func(pid int) error { return syscall.Kill(pid, syscall.SIGSTOP) },
+		cont:    func(pid int) error { return syscall.Kill(pid, syscall.SIGCONT) },
+		delay:   func(pid int) error { return syscall.Kill(pid, syscall.SIGUSR1) },
+		undelay: func(pid int) error { return syscall.Kill(pid, syscall.SIGUSR2) },
+	}
+}
+
+// delaySignals returns the signals switching the relay latency on and off.
+func delaySignals() (on, off os.Signal) {
+	return syscall.SIGUSR1, syscall.SIGUSR2
+}
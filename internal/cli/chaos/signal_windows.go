@@ -0,0 +1,34 @@
+//go:build windows
+
+package chaos
+
+import (
+	"os"
+
+	"github.com/roadrunner-server/errors"
+)
+
+// processSignaler kills the worker processes, processes can't be stopped and relays can't be delayed on windows.
+func processSignaler() signaler {
+	unsupported := func(int) error { return errors.Str("signaling processes is not supported on windows") }
+
+	return signaler{
+		kill: func(pid int) error {
+			p, err := os.FindProcess(pid)
+			if err != nil {
+				return err
+			}
+
+			return p.Kill()
+		},
+		stop:    unsupported,
+		cont:    unsupported,
+		delay:   unsupported,
+		undelay: unsupported,
+	}
+}
+
+// delaySignals returns no signals, there are no user signals on windows.
+func delaySignals() (on, off os.Signal) {
+	return nil, nil
+}
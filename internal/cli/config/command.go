@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCommand creates `config` command.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Configuration tools",
+	}
+
+	cmd.AddCommand(newEnvCommand())
+
+	return cmd
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/envconfig"
+
+	"github.com/spf13/cobra"
+)
+
+func newEnvCommand() *cobra.Command {
+	var set bool
+
+	cmd := &cobra.Command{
+		Use:   "env [prefix]",
+		Short: "List the environment variables configuring RoadRunner without the configuration file",
+		Long: `List the RR_ prefixed environment variables used to build the configuration when the configuration file
+(.rr.yaml) does not exist. The variable name is the configuration key in upper case with the dots replaced by
+underscores: RR_HTTP_POOL_NUM_WORKERS is http.pool.num_workers. <NAME> stands for the name of the map entry (kv
+storage, jobs pipeline, log channel, etc.), <KEY> for the arbitrary key (environment variable, header) and <N> for
+the list index. Lists are comma-separated or written in the YAML flow form ("[a, b]"), other values are parsed as
+YAML scalars.`,
+		Example: `  rr config env http
+  RR_HTTP_ADDRESS=0.0.0.0:8080 RR_KV_LOCAL_DRIVER=memory rr config env --set`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var prefix string
+			if len(args) > 0 {
+				prefix = args[0]
+			}
+
+			if set {
+				renderSet(cmd.OutOrStdout(), os.Environ(), prefix)
+
+				return nil
+			}
+
+			render(cmd.OutOrStdout(), envconfig.Variables(), prefix)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&set, "set", false, "show the variables set in the current environment with their keys")
+
+	return cmd
+}
+
+// matches reports whether the variable name or key starts with the prefix (case-insensitive, RR_ is optional).
+func matches(name, key, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+
+	p := strings.TrimPrefix(strings.ToUpper(prefix), envconfig.Prefix)
+
+	return strings.HasPrefix(strings.TrimPrefix(name, envconfig.Prefix), p) ||
+		strings.HasPrefix(strings.ToUpper(key), p)
+}
+
+func render(w io.Writer, vars []envconfig.Variable, prefix string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:gomnd
+	_, _ = fmt.Fprintln(tw, "VARIABLE\tKEY")
+
+	for _, v := range vars {
+		if !matches(v.Name, v.Key, prefix) {
+			continue
+		}
+
+		key := v.Key
+		if v.List {
+			key += " (list)"
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", v.Name, key)
+	}
+
+	_ = tw.Flush()
+}
+
+// renderSet shows the configuration variables of the environment, unknown ones are reported as well.
+func renderSet(w io.Writer, environ []string, prefix string) {
+	names := make([]string, 0)
+
+	for _, kv := range environ {
+		if name, _, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(name, envconfig.Prefix) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:gomnd
+	_, _ = fmt.Fprintln(tw, "VARIABLE\tKEY")
+
+	for _, name := range names {
+		key, ok := envconfig.Resolve(name)
+		if !matches(name, key, prefix) {
+			continue
+		}
+
+		switch {
+		case envconfig.Runtime(name):
+			key = "(runtime, not a configuration key)"
+		case !ok:
+			key = "(unknown)"
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", name, key)
+	}
+
+	_ = tw.Flush()
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/envconfig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	buf := &bytes.Buffer{}
+	render(buf, envconfig.Variables(), "rr_http_pool")
+
+	out := buf.String()
+	assert.Contains(t, out, "RR_HTTP_POOL_NUM_WORKERS")
+	assert.Contains(t, out, "http.pool.num_workers")
+	assert.NotContains(t, out, "RR_HTTP_ADDRESS")
+
+	buf.Reset()
+	render(buf, envconfig.Variables(), "")
+	assert.Contains(t, buf.String(), "RR_KV_<NAME>_DRIVER")
+	assert.Contains(t, buf.String(), "RR_SERVER_ENV_<KEY>")
+	assert.Contains(t, buf.String(), "RR_EXTERNAL_PLUGINS_<N>_NAME")
+	assert.Contains(t, buf.String(), "http.middleware (list)")
+}
+
+func TestRenderSet(t *testing.T) {
+	buf := &bytes.Buffer{}
+	renderSet(buf, []string{
+		"PATH=/bin",
+		"RR_RPC=tcp://127.0.0.1:6001",
+		"RR_HTTP_ADDRESS=:8080",
+		"RR_KV_LOCAL_DRIVER=memory",
+		"RR_HTTP_ADRESS=:8080",
+	}, "")
+
+	out := buf.String()
+	assert.NotContains(t, out, "PATH")
+	assert.Regexp(t, `RR_HTTP_ADDRESS\s+http.address`, out)
+	assert.Regexp(t, `RR_KV_LOCAL_DRIVER\s+kv.local.driver`, out)
+	assert.Regexp(t, `RR_HTTP_ADRESS\s+\(unknown\)`, out)
+	assert.Regexp(t, `RR_RPC\s+\(runtime`, out)
+}
+
+func TestCommand(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	cmd := NewCommand()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"env", "rpc"})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "RR_RPC_LISTEN")
+}
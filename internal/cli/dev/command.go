@@ -0,0 +1,111 @@
+package dev
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/serve"
+	dbg "github.com/roadrunner-server/roadrunner/v2/internal/debug"
+	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+
+	configImpl "github.com/roadrunner-server/config/v2"
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// NewCommand creates `dev` command.
+func NewCommand(cfgFile *string, override *[]string, silent *bool, debugAddr *string) *cobra.Command {
+	var (
+		// single worker with a single job
+		single bool
+		// do not print the configuration
+		quiet bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Start RoadRunner server with the local development profile",
+		Long: `Start RoadRunner server with the local development profile applied on top of the configuration:
+
+  - workers are reloaded when the project files change (reload plugin)
+  - development logs at debug level, workers stderr is printed as is
+  - debug server on localhost, the configuration is reloaded when the config file changes
+  - a single worker executing a single job (--single)
+
+User overrides (-o) take precedence over the profile.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			const op = errors.Op("rr_dev")
+
+			if cfgFile == nil {
+				return errors.E(op, errors.Str("no configuration file provided"))
+			}
+
+			cfg := &configImpl.Plugin{Path: *cfgFile, Prefix: "rr", Flags: *override, Version: meta.Version()}
+			if err := cfg.Init(); err != nil {
+				return errors.E(op, err)
+			}
+
+			// the working directory is the project directory (see the root command)
+			ov := append(profile(cfg, ".", single), *override...)
+
+			// the effective configuration
+			cfg = &configImpl.Plugin{Path: *cfgFile, Prefix: "rr", Flags: ov, Version: meta.Version()}
+			if err := cfg.Init(); err != nil {
+				return errors.E(op, err)
+			}
+
+			addr := *debugAddr
+			if addr == "" {
+				dbgCfg, err := dbg.NewConfig(*cfgFile)
+				if err != nil {
+					return errors.E(op, err)
+				}
+
+				addr = debugAddress(dbgCfg.Address)
+			}
+
+			if err := printProfile(cmd.OutOrStdout(), cfg, quiet); err != nil {
+				return errors.E(op, err)
+			}
+
+			debug := true
+			srv := serve.NewCommand(&ov, cfgFile, silent, &debug, &addr)
+
+			if err := srv.Flags().Set("watch-config", "true"); err != nil {
+				return errors.E(op, err)
+			}
+
+			return srv.RunE(srv, nil)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&single, "single", false, "a single worker restarted after every job (num_workers=1, max_jobs=1)")
+	f.BoolVarP(&quiet, "quiet", "q", false, "do not print the configuration at startup")
+
+	return cmd
+}
+
+// printProfile prints the effective configuration and the listening URLs.
+func printProfile(w io.Writer, cfg *configImpl.Plugin, quiet bool) error {
+	if !quiet {
+		all := make(map[string]interface{})
+		if err := cfg.Unmarshal(&all); err != nil {
+			return err
+		}
+
+		out, err := yaml.Marshal(all)
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(w, "# effective configuration\n%s\n", out)
+	}
+
+	for _, u := range listenURLs(cfg) {
+		_, _ = fmt.Fprintf(w, "[INFO] %s: %s\n", u[0], u[1])
+	}
+
+	return nil
+}
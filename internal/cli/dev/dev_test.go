@@ -0,0 +1,104 @@
+package dev
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configImpl "github.com/roadrunner-server/config/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cfg = `
+version: "2.7"
+rpc:
+  listen: tcp://127.0.0.1:6001
+logs:
+  mode: production
+  level: error
+  channels:
+    http:
+      level: panic
+http:
+  address: 0.0.0.0:8080
+  pool:
+    num_workers: 8
+jobs:
+  pool:
+    num_workers: 4
+reload:
+  services:
+    jobs:
+      dirs: [ "src/Jobs" ]
+metrics:
+  address: 127.0.0.1:2112
+`
+
+func newConfig(t *testing.T, override []string) *configImpl.Plugin {
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(cfg), 0o600))
+
+	c := &configImpl.Plugin{Path: path, Prefix: "rr", Flags: override}
+	require.NoError(t, c.Init())
+
+	return c
+}
+
+func TestProfile(t *testing.T) {
+	ov := profile(newConfig(t, nil), ".", true)
+
+	assert.Equal(t, []string{
+		"logs.mode=development",
+		"logs.level=debug",
+		"logs.channels.http.mode=development",
+		"logs.channels.http.level=debug",
+		"logs.channels.server.mode=raw",
+		"logs.channels.server.level=debug",
+		"reload.services.http.dirs=.",
+		"reload.services.http.recursive=true",
+		"http.pool.num_workers=1",
+		"http.pool.max_jobs=1",
+		"jobs.pool.num_workers=1",
+		"jobs.pool.max_jobs=1",
+	}, ov)
+
+	// user overrides take precedence over the profile
+	c := newConfig(t, append(ov, "http.pool.num_workers=2"))
+
+	assert.Equal(t, "development", c.Get("logs.mode"))
+	assert.Equal(t, "debug", c.Get("logs.channels.http.level"))
+	assert.Equal(t, "2", c.Get("http.pool.num_workers"))
+	assert.Equal(t, []interface{}{"src/Jobs"}, c.Get("reload.services.jobs.dirs"))
+
+	var reload struct {
+		Services map[string]struct {
+			Dirs      []string `mapstructure:"dirs"`
+			Recursive bool     `mapstructure:"recursive"`
+		} `mapstructure:"services"`
+	}
+
+	require.NoError(t, c.UnmarshalKey("reload", &reload))
+	assert.Equal(t, []string{"."}, reload.Services["http"].Dirs)
+	assert.True(t, reload.Services["http"].Recursive)
+	assert.Equal(t, []string{"src/Jobs"}, reload.Services["jobs"].Dirs)
+}
+
+func TestDebugAddress(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:6061", debugAddress("0.0.0.0:6061"))
+	assert.Equal(t, "127.0.0.1:6061", debugAddress(":6061"))
+	assert.Equal(t, "localhost:7000", debugAddress("localhost:7000"))
+	assert.Equal(t, "[::1]:7000", debugAddress("[::1]:7000"))
+}
+
+func TestPrintProfile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, printProfile(buf, newConfig(t, []string{"logs.mode=development"}), false))
+
+	out := buf.String()
+	assert.Contains(t, out, "mode: development")
+	assert.Contains(t, out, "[INFO] http: http://127.0.0.1:8080\n")
+	assert.Contains(t, out, "[INFO] rpc: tcp://127.0.0.1:6001\n")
+	assert.Contains(t, out, "[INFO] metrics: http://127.0.0.1:2112/metrics\n")
+}
@@ -0,0 +1,136 @@
+package dev
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// configurer is the part of the config plugin used to build the profile.
+type configurer interface {
+	Has(name string) bool
+	Get(name string) interface{}
+}
+
+// workerPlugins are the plugins with a workers pool: reloaded on the project files change, pool key for --single.
+var workerPlugins = []struct{ name, pool string }{ //nolint:gochecknoglobals
+	{name: "http", pool: "http.pool"},
+	{name: "grpc", pool: "grpc.pool"},
+	{name: "jobs", pool: "jobs.pool"},
+	{name: "tcp", pool: "tcp.pool"},
+	{name: "temporal", pool: "temporal.activities"},
+}
+
+// profile returns the developer profile overrides for the configuration, values set by the user are kept.
+func profile(cfg configurer, dir string, single bool) []string {
+	ov := []string{"logs.mode=development", "logs.level=debug"}
+
+	// channels override the global logs settings
+	channels, _ := cfg.Get("logs.channels").(map[string]interface{})
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name != "server" {
+			ov = append(ov,
+				fmt.Sprintf("logs.channels.%s.mode=development", name),
+				fmt.Sprintf("logs.channels.%s.level=debug", name),
+			)
+		}
+	}
+
+	// workers stderr is logged by the server plugin, raw mode prints it as is (var_dump, error_log)
+	ov = append(ov, "logs.channels.server.mode=raw", "logs.channels.server.level=debug")
+
+	for _, p := range workerPlugins {
+		if !cfg.Has(p.name) {
+			continue
+		}
+
+		if !cfg.Has("reload.services." + p.name) {
+			ov = append(ov,
+				fmt.Sprintf("reload.services.%s.dirs=%s", p.name, dir),
+				fmt.Sprintf("reload.services.%s.recursive=true", p.name),
+			)
+		}
+
+		if single {
+			ov = append(ov, p.pool+".num_workers=1", p.pool+".max_jobs=1")
+		}
+	}
+
+	return ov
+}
+
+// debugAddress returns the debug server address bound to the localhost.
+func debugAddress(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		host = "127.0.0.1"
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+// listenURLs returns the addresses RoadRunner listens on, by plugin.
+func listenURLs(cfg configurer) [][2]string {
+	var urls [][2]string
+
+	add := func(name, scheme, key, path string) {
+		addr, _ := cfg.Get(key).(string)
+		if addr == "" {
+			return
+		}
+
+		if scheme != "" {
+			addr = scheme + "://" + localhost(addr) + path
+		}
+
+		urls = append(urls, [2]string{name, addr})
+	}
+
+	add("http", "http", "http.address", "")
+	add("https", "https", "http.ssl.address", "")
+	add("grpc", "", "grpc.listen", "")
+
+	servers, _ := cfg.Get("tcp.servers").(map[string]interface{})
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		add("tcp "+name, "tcp", "tcp.servers."+name+".addr", "")
+	}
+
+	add("rpc", "", "rpc.listen", "")
+	add("metrics", "http", "metrics.address", "/metrics")
+	add("status", "http", "status.address", "/health?plugin=http")
+
+	return urls
+}
+
+// localhost replaces the unspecified host to get a clickable URL.
+func localhost(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	if host == "" || strings.Trim(host, "0.:[]") == "" {
+		host = "127.0.0.1"
+	}
+
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,204 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+	"github.com/roadrunner-server/roadrunner/v2/internal/workerenv"
+
+	"github.com/joho/godotenv"
+	configImpl "github.com/roadrunner-server/config/v2"
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+// target is the resolved environment of the plugin workers.
+type target struct {
+	Plugin    string     `json:"plugin"`
+	Command   string     `json:"command"`
+	User      string     `json:"user,omitempty"`
+	Variables []variable `json:"variables"`
+}
+
+// NewCommand creates `env` command.
+func NewCommand(cfgFile *string, override *[]string, dotenv *string) *cobra.Command { //nolint:funlen
+	var (
+		showSecrets bool
+		changed     bool
+		format      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "env [plugin...]",
+		Short: "Show the environment of the plugin workers with the origin of every variable",
+		Long: `Show the environment the workers of the plugins (http, grpc, jobs, tcp, temporal or on_init) receive, all
+configured by default. Variables are merged the same way the server plugin does it: process environment (with the
+dotenv file loaded), RR_RELAY and RR_RPC, server.env and the variables of the plugin. Values of the variables with
+secret names and passwords in URLs are masked.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			const op = errors.Op("rr_env")
+
+			if format != "text" && format != "json" {
+				return errors.E(op, fmt.Errorf("unknown format `%s` (allowed: text, json)", format))
+			}
+
+			if cfgFile == nil {
+				return errors.E(op, errors.Str("no configuration file provided"))
+			}
+
+			cfg := &configImpl.Plugin{Path: *cfgFile, Prefix: "rr", Flags: *override, Version: meta.Version()}
+			if err := cfg.Init(); err != nil {
+				return errors.E(op, err)
+			}
+
+			wc, err := workerenv.FromConfig(cfg)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			// dotenv file is loaded by the root command already, read it once more to know its variables
+			var (
+				dotenvPath string
+				fromFile   map[string]string
+			)
+
+			if dotenv != nil && *dotenv != "" {
+				dotenvPath = *dotenv
+				if fromFile, err = godotenv.Read(dotenvPath); err != nil {
+					return errors.E(op, err)
+				}
+			}
+
+			names, err := targetNames(cfg, args)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			process := processSources(os.Environ(), fromFile, dotenvPath)
+			targets := make([]*target, 0, len(names))
+
+			for _, name := range names {
+				sources, errS := targetSources(name, wc, cfg, process)
+				if errS != nil {
+					return errors.E(op, errS)
+				}
+
+				t := &target{Plugin: name, Command: wc.Command, User: wc.User, Variables: merge(sources)}
+				if name == targetOnInit {
+					t.Command, _ = cfg.Get("server.on_init.command").(string)
+				}
+
+				if changed {
+					t.Variables = withoutProcess(t.Variables)
+				}
+
+				if !showSecrets {
+					mask(t.Variables)
+				}
+
+				targets = append(targets, t)
+			}
+
+			if format == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+
+				return enc.Encode(targets)
+			}
+
+			render(cmd.OutOrStdout(), targets)
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&showSecrets, "show-secrets", false, "do not mask the secret values")
+	f.BoolVar(&changed, "changed", false, "hide the variables inherited from the process environment as is")
+	f.StringVar(&format, "format", "text", "output format: text, json")
+
+	return cmd
+}
+
+// targetNames returns the requested targets or all configured ones.
+func targetNames(cfg workerenv.Configurer, args []string) ([]string, error) {
+	known := append(append([]string{}, workerPlugins...), targetOnInit)
+
+	for _, arg := range args {
+		found := false
+		for _, k := range known {
+			found = found || k == arg
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown plugin `%s` (allowed: %s)", arg, strings.Join(known, ", "))
+		}
+	}
+
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	var names []string
+
+	for _, name := range workerPlugins {
+		if cfg.Has(name) {
+			names = append(names, name)
+		}
+	}
+
+	if cfg.Has("server.on_init") {
+		names = append(names, targetOnInit)
+	}
+
+	if len(names) == 0 {
+		return nil, errors.Str("no plugins with workers are configured")
+	}
+
+	return names, nil
+}
+
+// withoutProcess drops the variables inherited from the process environment and not overridden.
+func withoutProcess(vars []variable) []variable {
+	out := vars[:0]
+
+	for _, v := range vars {
+		if v.Origin != originProcess {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+func render(w io.Writer, targets []*target) {
+	for i, t := range targets {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+
+		header := fmt.Sprintf("Environment of [%s]: %s", t.Plugin, t.Command)
+		if t.User != "" {
+			header += fmt.Sprintf(" (user: %s)", t.User)
+		}
+
+		_, _ = fmt.Fprintln(w, header)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:gomnd
+		for _, v := range t.Variables {
+			origin := v.Origin
+			if len(v.Overrides) > 0 {
+				origin += " (overrides " + strings.Join(v.Overrides, ", ") + ")"
+			}
+
+			_, _ = fmt.Fprintf(tw, "  %s=%s\t%s\n", v.Name, v.Value, origin)
+		}
+
+		_ = tw.Flush()
+	}
+}
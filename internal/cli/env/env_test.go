@@ -0,0 +1,127 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	process := processSources(
+		[]string{"PATH=/bin", "APP_ENV=local", "DB_HOST=db"},
+		map[string]string{"APP_ENV": "dev", "DB_HOST": "db"},
+		".env",
+	)
+
+	vars := merge(append(process,
+		source{origin: originServer, vars: map[string]string{"APP_ENV": "prod"}},
+	))
+
+	byName := make(map[string]variable)
+	for _, v := range vars {
+		byName[v.Name] = v
+	}
+
+	// loaded from the dotenv file
+	assert.Equal(t, variable{Name: "DB_HOST", Value: "db", Origin: "dotenv .env"}, byName["DB_HOST"])
+	assert.Equal(t, variable{Name: "PATH", Value: "/bin", Origin: originProcess}, byName["PATH"])
+	// process shadows dotenv, server.env overrides both
+	assert.Equal(t, variable{
+		Name: "APP_ENV", Value: "prod", Origin: originServer, Overrides: []string{"dotenv .env", originProcess},
+	}, byName["APP_ENV"])
+}
+
+func TestMask(t *testing.T) {
+	vars := []variable{
+		{Name: "DB_PASSWORD", Value: "secret"},
+		{Name: "API_TOKEN", Value: ""},
+		{Name: "DATABASE_URL", Value: "postgres://app:secret@db:5432/app"},
+		{Name: "APP_URL", Value: "https://example.com"},
+	}
+
+	mask(vars)
+
+	assert.Equal(t, masked, vars[0].Value)
+	assert.Equal(t, "", vars[1].Value)
+	assert.Equal(t, "postgres://app:xxxxx@db:5432/app", vars[2].Value)
+	assert.Equal(t, "https://example.com", vars[3].Value)
+}
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, ".rr.yaml")
+	dotenv := filepath.Join(dir, ".env")
+
+	require.NoError(t, os.WriteFile(cfg, []byte(`
+version: "2.7"
+rpc:
+  listen: tcp://127.0.0.1:6001
+server:
+  command: php worker.php
+  env:
+    app_secret: s3cr3t
+    app_env: prod
+  on_init:
+    command: php init.php
+    env:
+      init: "1"
+http:
+  address: 127.0.0.1:8080
+grpc:
+  listen: tcp://127.0.0.1:9001
+  env:
+    app_env: grpc
+`), 0o600))
+	require.NoError(t, os.WriteFile(dotenv, []byte("APP_ENV=dev\nRR_ENV_TEST_DOTENV=1\n"), 0o600))
+
+	buf := &bytes.Buffer{}
+
+	cmd := NewCommand(&cfg, &[]string{}, &dotenv)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--changed", "--format", "json"})
+	require.NoError(t, cmd.Execute())
+
+	var targets []*target
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &targets))
+	require.Len(t, targets, 3)
+
+	vars := func(i int) map[string]variable {
+		out := make(map[string]variable)
+		for _, v := range targets[i].Variables {
+			out[v.Name] = v
+		}
+
+		return out
+	}
+
+	assert.Equal(t, "http", targets[0].Plugin)
+	assert.Equal(t, "php worker.php", targets[0].Command)
+
+	http := vars(0)
+	assert.Equal(t, "http", http["RR_MODE"].Value)
+	assert.Equal(t, "pipes", http["RR_RELAY"].Value)
+	assert.Equal(t, "tcp://127.0.0.1:6001", http["RR_RPC"].Value)
+	assert.Equal(t, masked, http["APP_SECRET"].Value)
+	assert.Equal(t, "prod", http["APP_ENV"].Value)
+	assert.Equal(t, []string{"dotenv " + dotenv}, http["APP_ENV"].Overrides)
+	assert.Equal(t, "dotenv "+dotenv, http["RR_ENV_TEST_DOTENV"].Origin)
+
+	assert.Equal(t, "grpc", targets[1].Plugin)
+	assert.Equal(t, variable{
+		Name: "APP_ENV", Value: "grpc", Origin: "grpc.env", Overrides: []string{"dotenv " + dotenv, originServer},
+	}, vars(1)["APP_ENV"])
+
+	// on_init command gets neither the server.env nor the RoadRunner variables
+	assert.Equal(t, "on_init", targets[2].Plugin)
+	assert.Equal(t, "php init.php", targets[2].Command)
+
+	onInit := vars(2)
+	assert.Equal(t, originOnInit, onInit["INIT"].Origin)
+	assert.NotContains(t, onInit, "RR_RELAY")
+	assert.NotContains(t, onInit, "APP_SECRET")
+}
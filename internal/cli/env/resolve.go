@@ -0,0 +1,170 @@
+package env
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/workerenv"
+	"github.com/roadrunner-server/roadrunner/v2/pkg/worker"
+)
+
+const (
+	originProcess = "process"
+	originRR      = "rr"
+	originServer  = "server.env"
+	originOnInit  = "server.on_init.env"
+	// on_init is not a plugin, but its command gets its own environment
+	targetOnInit = "on_init"
+	masked       = "xxxxx"
+)
+
+// workerPlugins are the plugins with a workers pool, in the default order.
+var workerPlugins = []string{"http", "grpc", "jobs", "tcp", "temporal"} //nolint:gochecknoglobals
+
+// secretName matches the names of the variables with secret values.
+var secretName = regexp.MustCompile( //nolint:gochecknoglobals
+	`(?i)(pass|secret|token|key|credential|auth|private|cookie|salt)`,
+)
+
+// source is a set of variables from a single origin.
+type source struct {
+	origin string
+	vars   map[string]string
+}
+
+// variable is the resolved worker variable.
+type variable struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+	// origins of the overridden values
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// merge merges the sources, later sources take precedence, variables are sorted by name.
+func merge(sources []source) []variable {
+	vars := make(map[string]*variable)
+
+	for _, src := range sources {
+		for name, value := range src.vars {
+			v, ok := vars[name]
+			if !ok {
+				vars[name] = &variable{Name: name, Value: value, Origin: src.origin}
+
+				continue
+			}
+
+			v.Overrides = append(v.Overrides, v.Origin)
+			v.Value, v.Origin = value, src.origin
+		}
+	}
+
+	out := make([]variable, 0, len(vars))
+	for _, v := range vars {
+		out = append(out, *v)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out
+}
+
+// processSources splits the process environment into the dotenv file variables and the rest. Dotenv does not
+// override the process variables, so a different process value shadows the dotenv one.
+func processSources(environ []string, dotenv map[string]string, dotenvPath string) []source {
+	process := make(map[string]string, len(environ))
+
+	for _, kv := range environ {
+		if name, value, ok := strings.Cut(kv, "="); ok && name != "" {
+			process[name] = value
+		}
+	}
+
+	if len(dotenv) == 0 {
+		return []source{{origin: originProcess, vars: process}}
+	}
+
+	fromFile := make(map[string]string, len(dotenv))
+
+	for name, value := range dotenv {
+		fromFile[name] = value
+
+		if process[name] == value {
+			delete(process, name)
+		}
+	}
+
+	return []source{{origin: "dotenv " + dotenvPath, vars: fromFile}, {origin: originProcess, vars: process}}
+}
+
+// targetSources returns the variables sources of the target workers in the order of precedence, same as the server
+// plugin: process, RR_RELAY and RR_RPC, server.env, plugin variables.
+func targetSources(
+	target string, wc *workerenv.Config, cfg workerenv.Configurer, process []source,
+) ([]source, error) {
+	if target == targetOnInit {
+		onInit := make(map[string]string)
+		if err := cfg.UnmarshalKey("server.on_init.env", &onInit); err != nil {
+			return nil, err
+		}
+
+		// the on_init command gets the process variables appended after its own
+		return append([]source{{origin: originOnInit, vars: upper(onInit)}}, process...), nil
+	}
+
+	rr := map[string]string{worker.EnvRelay: wc.Relay}
+	if wc.RPC != "" {
+		rr[workerenv.EnvRPC] = wc.RPC
+	}
+
+	sources := make([]source, 0, len(process)+4) //nolint:gomnd
+	sources = append(sources, process...)
+	sources = append(sources, source{origin: originRR, vars: rr}, source{origin: originServer, vars: upper(wc.Env)})
+
+	plugin := map[string]string{worker.EnvMode: target}
+
+	switch target {
+	case "grpc":
+		// grpc passes its own env section, RR_MODE is set over it
+		grpcEnv := make(map[string]string)
+		if err := cfg.UnmarshalKey("grpc.env", &grpcEnv); err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, source{origin: "grpc.env", vars: upper(grpcEnv)})
+	case "temporal":
+		plugin["RR_CODEC"] = "protobuf"
+	}
+
+	return append(sources, source{origin: originRR + " (" + target + ")", vars: plugin}), nil
+}
+
+// mask hides the values of the secret variables and the passwords in the URLs, same way as url.URL.Redacted.
+func mask(vars []variable) {
+	for i := range vars {
+		if secretName.MatchString(vars[i].Name) {
+			if vars[i].Value != "" {
+				vars[i].Value = masked
+			}
+
+			continue
+		}
+
+		if u, err := url.Parse(vars[i].Value); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				vars[i].Value = u.Redacted()
+			}
+		}
+	}
+}
+
+func upper(env map[string]string) map[string]string {
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		out[strings.ToUpper(k)] = v
+	}
+
+	return out
+}
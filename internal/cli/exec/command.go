@@ -0,0 +1,163 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	osExec "os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	internalRpc "github.com/roadrunner-server/roadrunner/v2/internal/rpc"
+	"github.com/roadrunner-server/roadrunner/v2/internal/workerenv"
+
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+// ExitError carries the exit code of the executed command, which has already reported the failure itself, so the
+// error message is empty.
+type ExitError struct {
+	Code int
+}
+
+// Error implements error interface.
+func (e *ExitError) Error() string { return "" }
+
+// ExitCode returns process exit code.
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// NewCommand creates `exec` command.
+func NewCommand(cfgFile *string, override *[]string) *cobra.Command {
+	var (
+		// RoadRunner RPC should be reachable
+		requireRPC bool
+		rpcTimeout time.Duration
+		// additional variables
+		env []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "exec -- command [args...]",
+		Short: "Run a one-off command with the workers environment (server.env, user, group, RR_RPC)",
+		Example: `  rr exec -- php artisan migrate
+  rr exec --rpc -- php bin/console app:warmup`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			const op = errors.Op("rr_exec")
+
+			if cfgFile == nil {
+				return errors.E(op, errors.Str("no configuration file provided"))
+			}
+
+			cfg, err := workerenv.Read(*cfgFile, *override)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			if requireRPC {
+				if err = waitRPC(cfg.RPC, rpcTimeout); err != nil {
+					return errors.E(op, err)
+				}
+			}
+
+			for _, e := range env {
+				if !strings.Contains(e, "=") {
+					return errors.E(op, fmt.Errorf("invalid variable `%s` (KEY=value)", e))
+				}
+			}
+
+			c := osExec.Command(args[0], args[1:]...) //nolint:gosec
+			c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+			c.Env = append(cfg.Environ(), env...)
+
+			if err = workerenv.Credential(c, cfg.User, cfg.Group); err != nil {
+				return errors.E(op, err)
+			}
+
+			return run(c)
+		},
+	}
+
+	f := cmd.Flags()
+	// flags after the command belong to it
+	f.SetInterspersed(false)
+	f.BoolVar(&requireRPC, "rpc", false, "wait for the RoadRunner RPC (rpc.listen) before running the command")
+	f.DurationVar(&rpcTimeout, "rpc-timeout", time.Second*10, "how long to wait for the RoadRunner RPC")
+	f.StringArrayVarP(&env, "env", "e", nil, "additional environment variable (KEY=value)")
+
+	return cmd
+}
+
+// run runs the command forwarding the termination signals, non-zero exit code is returned as ExitError.
+func run(c *osExec.Cmd) error {
+	// the terminal sends the interrupt to the command as well, rr waits for the command to exit
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	defer signal.Stop(sig)
+
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case s := <-sig:
+				if s != os.Interrupt {
+					_ = c.Process.Signal(s)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := c.Wait()
+	if err == nil {
+		return nil
+	}
+
+	if c.ProcessState == nil {
+		return err
+	}
+
+	ws, ok := c.ProcessState.Sys().(syscall.WaitStatus)
+	if !ok {
+		return err
+	}
+
+	// shell convention for the commands killed by a signal
+	if ws.Signaled() {
+		return &ExitError{Code: 128 + int(ws.Signal())}
+	}
+
+	return &ExitError{Code: ws.ExitStatus()}
+}
+
+// waitRPC waits until RoadRunner accepts RPC connections.
+func waitRPC(addr string, timeout time.Duration) error {
+	if addr == "" {
+		return errors.Str("rpc.listen is not configured")
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		conn, err := internalRpc.Dialer(addr)
+		if err == nil {
+			return conn.Close()
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("RoadRunner RPC is not reachable on %s: %w", addr, err)
+		}
+
+		time.Sleep(time.Millisecond * 100)
+	}
+}
@@ -0,0 +1,73 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envTestOut = "RR_EXEC_TEST_OUT"
+
+func TestMain(m *testing.M) {
+	// launched by the command under the test: dump the environment and fail
+	if out := os.Getenv(envTestOut); out != "" {
+		data := fmt.Sprintf("%s|%s|%s", os.Getenv("APP_ENV"), os.Getenv("RR_RPC"), os.Getenv("EXTRA"))
+		if err := os.WriteFile(out, []byte(data), 0o600); err != nil {
+			os.Exit(1)
+		}
+
+		os.Exit(3)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, ".rr.yaml")
+	out := filepath.Join(dir, "env")
+
+	require.NoError(t, os.WriteFile(cfg, []byte(`
+version: "2.7"
+rpc:
+  listen: tcp://127.0.0.1:6001
+server:
+  command: php worker.php
+  env:
+    app_env: ${RR_EXEC_TEST_STAGE}
+`), 0o600))
+
+	t.Setenv("RR_EXEC_TEST_STAGE", "testing")
+
+	cmd := NewCommand(&cfg, &[]string{"rpc.listen=tcp://127.0.0.1:6002"})
+	cmd.SetArgs([]string{"-e", "EXTRA=1", "-e", envTestOut + "=" + out, os.Args[0], "-test.run=^$", "-v"})
+
+	err := cmd.Execute()
+
+	var ee *ExitError
+	require.True(t, errors.As(err, &ee), err)
+	assert.Equal(t, 3, ee.ExitCode())
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "testing|tcp://127.0.0.1:6002|1", string(data))
+}
+
+func TestWaitRPC(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := "tcp://" + l.Addr().String()
+	assert.NoError(t, waitRPC(addr, time.Second))
+
+	require.NoError(t, l.Close())
+	assert.Error(t, waitRPC(addr, time.Millisecond*200))
+	assert.Error(t, waitRPC("", time.Second))
+}
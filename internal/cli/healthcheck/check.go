@@ -0,0 +1,362 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/roadrunner-server/api/v2/plugins/jobs"
+	"github.com/roadrunner-server/api/v2/plugins/status"
+	"github.com/roadrunner-server/informer/v2"
+)
+
+// Status is a Nagios compatible check state. Its numeric value is used as the process exit code.
+type Status int
+
+const (
+	OK       Status = 0
+	Warning  Status = 1
+	Critical Status = 2
+	Unknown  Status = 3
+)
+
+const (
+	informerList    string = "informer.List"
+	informerWorkers string = "informer.Workers"
+	informerJobs    string = "informer.Jobs"
+	statusHealth    string = "status.Status"
+	statusReady     string = "status.Ready"
+
+	// wildcard threshold key, applied to every plugin or pipeline
+	anyKey string = "*"
+)
+
+// String returns Nagios state name.
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "OK"
+	case Warning:
+		return "WARNING"
+	case Critical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalJSON encodes status as its name.
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Check is a result of the single assertion.
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	// Nagios performance data (label=value;warn;crit)
+	Perf string `json:"-"`
+}
+
+// Report aggregates all checks, its status is the worst status of the checks.
+type Report struct {
+	Status Status  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// Add appends check to the report and escalates report status.
+func (r *Report) Add(c Check) {
+	r.Checks = append(r.Checks, c)
+
+	if c.Status > r.Status {
+		r.Status = c.Status
+	}
+}
+
+// Caller is the part of the RPC client used by the checks.
+type Caller interface {
+	Call(serviceMethod string, args any, reply any) error
+}
+
+// Thresholds to assert. Map keys are plugin (or pipeline) names, `*` matches all of them.
+type Thresholds struct {
+	// plugins to request the health status from the status plugin
+	Health []string
+	// plugins to request the readiness status from the status plugin
+	Ready []string
+	// minimum number of ready workers per plugin
+	MinReadyWorkers map[string]int
+	// maximum memory per worker (bytes) per plugin
+	MaxWorkerMemory map[string]uint64
+	// maximum number of active+delayed+reserved jobs per pipeline
+	MaxJobsBacklog map[string]int64
+}
+
+// Run executes all configured checks using connected RPC client.
+func Run(client Caller, th *Thresholds) *Report {
+	r := &Report{}
+
+	// RPC itself is reachable and responsive
+	var plugins []string
+	if err := client.Call(informerList, true, &plugins); err != nil {
+		r.Add(Check{Name: "rpc", Status: Critical, Message: err.Error()})
+
+		return r
+	}
+
+	r.Add(Check{Name: "rpc", Status: OK, Message: fmt.Sprintf("%d informers", len(plugins))})
+
+	for _, p := range th.Health {
+		r.Add(statusCheck(client, statusHealth, "health", p))
+	}
+
+	for _, p := range th.Ready {
+		r.Add(statusCheck(client, statusReady, "ready", p))
+	}
+
+	if len(th.MinReadyWorkers) > 0 || len(th.MaxWorkerMemory) > 0 {
+		for _, p := range plugins {
+			_, minSet := lookup(th.MinReadyWorkers, p)
+			_, memSet := lookup(th.MaxWorkerMemory, p)
+
+			if !minSet && !memSet {
+				continue
+			}
+
+			list := &informer.WorkerList{}
+			if err := client.Call(informerWorkers, p, &list); err != nil {
+				r.Add(Check{Name: p + "_workers", Status: Unknown, Message: err.Error()})
+
+				continue
+			}
+
+			if min, ok := lookup(th.MinReadyWorkers, p); ok {
+				r.Add(readyWorkersCheck(p, list, min))
+			}
+
+			if max, ok := lookup(th.MaxWorkerMemory, p); ok {
+				r.Add(workerMemoryCheck(p, list, max))
+			}
+		}
+
+		// the explicitly named plugins should be running
+		for _, p := range missing(plugins, keys(th.MinReadyWorkers), keys(th.MaxWorkerMemory)) {
+			r.Add(Check{Name: p + "_workers", Status: Critical, Message: "plugin is not running"})
+		}
+	}
+
+	if len(th.MaxJobsBacklog) > 0 {
+		pipelines := make([]string, 0, len(th.MaxJobsBacklog))
+
+		for _, p := range plugins {
+			var jst []*jobs.State
+			if err := client.Call(informerJobs, p, &jst); err != nil {
+				r.Add(Check{Name: p + "_jobs", Status: Unknown, Message: err.Error()})
+
+				continue
+			}
+
+			for i := 0; i < len(jst); i++ {
+				pipelines = append(pipelines, jst[i].Pipeline)
+
+				if max, ok := lookup(th.MaxJobsBacklog, jst[i].Pipeline); ok {
+					r.Add(backlogCheck(jst[i], max))
+				}
+			}
+		}
+
+		// the explicitly named pipelines should exist
+		for _, p := range missing(pipelines, keys(th.MaxJobsBacklog)) {
+			r.Add(Check{Name: p + "_backlog", Status: Critical, Message: "pipeline not found"})
+		}
+	}
+
+	return r
+}
+
+// missing returns the sorted threshold names (except the wildcard) which are not in the found list.
+func missing(found []string, names ...[]string) []string {
+	seen := make(map[string]struct{}, len(found))
+	for _, f := range found {
+		seen[f] = struct{}{}
+	}
+
+	var out []string
+
+	for _, nn := range names {
+		for _, name := range nn {
+			if _, ok := seen[name]; ok || name == anyKey {
+				continue
+			}
+
+			seen[name] = struct{}{}
+			out = append(out, name)
+		}
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+func statusCheck(client Caller, method, kind, plugin string) Check {
+	name := plugin + "_" + kind
+	st := &status.Status{}
+
+	if err := client.Call(method, plugin, st); err != nil {
+		return Check{Name: name, Status: Critical, Message: err.Error()}
+	}
+
+	if st.Code < 200 || st.Code >= 300 {
+		return Check{Name: name, Status: Critical, Message: fmt.Sprintf("status code %d", st.Code)}
+	}
+
+	return Check{Name: name, Status: OK, Message: fmt.Sprintf("status code %d", st.Code)}
+}
+
+// readyWorkersCheck is WARNING when some but fewer than required workers are ready and CRITICAL when no worker is
+// ready at all.
+func readyWorkersCheck(plugin string, list *informer.WorkerList, min int) Check {
+	ready := 0
+
+	for i := 0; i < len(list.Workers); i++ {
+		if list.Workers[i].Status == "ready" || list.Workers[i].Status == "working" {
+			ready++
+		}
+	}
+
+	c := Check{
+		Name:    plugin + "_ready_workers",
+		Status:  OK,
+		Message: fmt.Sprintf("%d of %d workers ready (min %d)", ready, len(list.Workers), min),
+		// lower bound ranges: alert below the thresholds
+		Perf: fmt.Sprintf("%s_ready_workers=%d;%d:;1:", plugin, ready, min),
+	}
+
+	switch {
+	case ready == 0 && min > 0:
+		c.Status = Critical
+	case ready < min:
+		c.Status = Warning
+	}
+
+	return c
+}
+
+func workerMemoryCheck(plugin string, list *informer.WorkerList, max uint64) Check {
+	var (
+		maxSeen uint64
+		pid     int
+	)
+
+	for i := 0; i < len(list.Workers); i++ {
+		if list.Workers[i].MemoryUsage > maxSeen {
+			maxSeen = list.Workers[i].MemoryUsage
+			pid = list.Workers[i].Pid
+		}
+	}
+
+	c := Check{
+		Name:    plugin + "_worker_memory",
+		Status:  OK,
+		Message: fmt.Sprintf("max worker memory %s (limit %s)", humanize.Bytes(maxSeen), humanize.Bytes(max)),
+		Perf:    fmt.Sprintf("%s_worker_memory=%dB;;%d", plugin, maxSeen, max),
+	}
+
+	if maxSeen > max {
+		c.Status = Critical
+		c.Message = fmt.Sprintf("worker %d uses %s (limit %s)", pid, humanize.Bytes(maxSeen), humanize.Bytes(max))
+	}
+
+	return c
+}
+
+func backlogCheck(st *jobs.State, max int64) Check {
+	backlog := st.Active + st.Delayed + st.Reserved
+
+	c := Check{
+		Name:    st.Pipeline + "_backlog",
+		Status:  OK,
+		Message: fmt.Sprintf("%d jobs in backlog (max %d)", backlog, max),
+		Perf:    fmt.Sprintf("%s_backlog=%d;;%d", st.Pipeline, backlog, max),
+	}
+
+	if backlog > max {
+		c.Status = Critical
+	}
+
+	return c
+}
+
+func keys[T any](m map[string]T) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+
+	return out
+}
+
+func lookup[T any](m map[string]T, key string) (T, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+
+	v, ok := m[anyKey]
+
+	return v, ok
+}
+
+// Nagios renders report in the Nagios/Icinga plugin output format: status line with performance data followed by
+// the long output, one line per check.
+func (r *Report) Nagios() string {
+	var (
+		sb    strings.Builder
+		perf  []string
+		fails []string
+	)
+
+	for _, c := range r.Checks {
+		if c.Perf != "" {
+			perf = append(perf, c.Perf)
+		}
+
+		if c.Status != OK {
+			fails = append(fails, c.Name+": "+c.Message)
+		}
+	}
+
+	sb.WriteString("RR " + r.Status.String() + " - ")
+
+	if len(fails) == 0 {
+		sb.WriteString(strconv.Itoa(len(r.Checks)) + " checks passed")
+	} else {
+		sb.WriteString(strings.Join(fails, ", "))
+	}
+
+	if len(perf) > 0 {
+		sb.WriteString(" | " + strings.Join(perf, " "))
+	}
+
+	sb.WriteString("\n")
+
+	for _, c := range r.Checks {
+		sb.WriteString(fmt.Sprintf("[%s] %s: %s\n", c.Status, c.Name, c.Message))
+	}
+
+	return sb.String()
+}
+
+// JSON renders report as JSON document.
+func (r *Report) JSON() (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,178 @@
+package healthcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	internalRpc "github.com/roadrunner-server/roadrunner/v2/internal/rpc"
+
+	"github.com/dustin/go-humanize"
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatNagios string = "nagios"
+	formatJSON   string = "json"
+)
+
+// ExitError carries the check status, which should be used as the process exit code. The report is already printed,
+// so the error message is empty.
+type ExitError struct {
+	Status Status
+}
+
+// Error implements error interface.
+func (e *ExitError) Error() string { return "" }
+
+// ExitCode returns process exit code.
+func (e *ExitError) ExitCode() int { return int(e.Status) }
+
+// NewCommand creates `healthcheck` command.
+func NewCommand(cfgFile *string, override *[]string) *cobra.Command { //nolint:funlen
+	var (
+		format          string
+		timeout         time.Duration
+		health          []string
+		ready           []string
+		minReadyWorkers []string
+		maxWorkerMemory []string
+		maxJobsBacklog  []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Check RoadRunner health (Nagios compatible exit codes: 0 OK, 1 WARNING, 2 CRITICAL, 3 UNKNOWN)",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if format != formatNagios && format != formatJSON {
+				return fmt.Errorf("unknown output format `%s` (allowed: %s, %s)", format, formatNagios, formatJSON)
+			}
+
+			report := &Report{}
+			th, err := parseThresholds(health, ready, minReadyWorkers, maxWorkerMemory, maxJobsBacklog)
+
+			switch {
+			case err != nil:
+				report.Add(Check{Name: "config", Status: Unknown, Message: err.Error()})
+			case cfgFile == nil:
+				report.Add(Check{Name: "config", Status: Unknown, Message: "no configuration file provided"})
+			default:
+				report = runWithTimeout(*cfgFile, *override, th, timeout)
+			}
+
+			out := report.Nagios()
+			if format == formatJSON {
+				if out, err = report.JSON(); err != nil {
+					return &ExitError{Status: Unknown}
+				}
+			}
+
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), out)
+
+			if report.Status != OK {
+				return &ExitError{Status: report.Status}
+			}
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+
+	f.StringVarP(&format, "format", "", formatNagios, "output format (nagios, json)")
+	f.DurationVarP(&timeout, "timeout", "t", time.Second*5, "overall checks timeout")
+	f.StringSliceVarP(&health, "health", "", nil, "plugins to check via the status plugin health endpoint (e.g. http)")
+	f.StringSliceVarP(&ready, "ready", "", nil, "plugins to check via the status plugin readiness endpoint (e.g. http)")
+	f.StringArrayVarP(&minReadyWorkers, "min-ready-workers", "", nil, "minimum ready workers, plugin=N (* for all)")
+	f.StringArrayVarP(&maxWorkerMemory, "max-worker-memory", "", nil, "max memory per worker, plugin=256MB (* for all)")
+	f.StringArrayVarP(&maxJobsBacklog, "max-jobs-backlog", "", nil, "maximum jobs backlog, pipeline=N (* for all)")
+
+	return cmd
+}
+
+func runWithTimeout(cfgFile string, override []string, th *Thresholds, timeout time.Duration) *Report {
+	res := make(chan *Report, 1)
+
+	go func() {
+		client, err := internalRpc.NewClient(cfgFile, override)
+		if err != nil {
+			r := &Report{}
+			r.Add(Check{Name: "rpc", Status: Critical, Message: err.Error()})
+			res <- r
+
+			return
+		}
+
+		defer func() { _ = client.Close() }()
+
+		res <- Run(client, th)
+	}()
+
+	select {
+	case r := <-res:
+		return r
+	case <-time.After(timeout):
+		r := &Report{}
+		r.Add(Check{Name: "rpc", Status: Critical, Message: fmt.Sprintf("checks timed out after %s", timeout)})
+
+		return r
+	}
+}
+
+func parseThresholds(health, ready, minReady, maxMemory, maxBacklog []string) (*Thresholds, error) {
+	const op = errors.Op("healthcheck_parse_thresholds")
+
+	th := &Thresholds{
+		Health:          health,
+		Ready:           ready,
+		MinReadyWorkers: make(map[string]int, len(minReady)),
+		MaxWorkerMemory: make(map[string]uint64, len(maxMemory)),
+		MaxJobsBacklog:  make(map[string]int64, len(maxBacklog)),
+	}
+
+	for _, v := range minReady {
+		key, val := splitThreshold(v)
+
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, errors.E(op, fmt.Errorf("min-ready-workers `%s`: %w", v, err))
+		}
+
+		th.MinReadyWorkers[key] = n
+	}
+
+	for _, v := range maxMemory {
+		key, val := splitThreshold(v)
+
+		n, err := humanize.ParseBytes(val)
+		if err != nil {
+			return nil, errors.E(op, fmt.Errorf("max-worker-memory `%s`: %w", v, err))
+		}
+
+		th.MaxWorkerMemory[key] = n
+	}
+
+	for _, v := range maxBacklog {
+		key, val := splitThreshold(v)
+
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, errors.E(op, fmt.Errorf("max-jobs-backlog `%s`: %w", v, err))
+		}
+
+		th.MaxJobsBacklog[key] = n
+	}
+
+	return th, nil
+}
+
+// splitThreshold splits `name=value`, value without name applies to all plugins.
+func splitThreshold(v string) (string, string) {
+	if key, val, ok := strings.Cut(v, "="); ok {
+		return strings.TrimSpace(key), strings.TrimSpace(val)
+	}
+
+	return anyKey, strings.TrimSpace(v)
+}
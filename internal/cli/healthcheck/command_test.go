@@ -0,0 +1,145 @@
+package healthcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/roadrunner-server/api/v2/plugins/jobs"
+	"github.com/roadrunner-server/api/v2/plugins/status"
+	"github.com/roadrunner-server/api/v2/state/process"
+	"github.com/roadrunner-server/informer/v2"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/healthcheck"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandProperties(t *testing.T) {
+	cmd := healthcheck.NewCommand(nil, nil)
+
+	assert.Equal(t, "healthcheck", cmd.Use)
+	assert.NotNil(t, cmd.RunE)
+}
+
+func TestCommandFlags(t *testing.T) {
+	cmd := healthcheck.NewCommand(nil, nil)
+
+	cases := []struct {
+		giveName      string
+		wantShorthand string
+		wantDefault   string
+	}{
+		{giveName: "format", wantShorthand: "", wantDefault: "nagios"},
+		{giveName: "timeout", wantShorthand: "t", wantDefault: "5s"},
+		{giveName: "health", wantShorthand: "", wantDefault: "[]"},
+		{giveName: "ready", wantShorthand: "", wantDefault: "[]"},
+		{giveName: "min-ready-workers", wantShorthand: "", wantDefault: "[]"},
+		{giveName: "max-worker-memory", wantShorthand: "", wantDefault: "[]"},
+		{giveName: "max-jobs-backlog", wantShorthand: "", wantDefault: "[]"},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.giveName, func(t *testing.T) {
+			flag := cmd.Flag(tt.giveName)
+
+			if flag == nil {
+				assert.Failf(t, "flag not found", "flag [%s] was not found", tt.giveName)
+
+				return
+			}
+
+			assert.Equal(t, tt.wantShorthand, flag.Shorthand)
+			assert.Equal(t, tt.wantDefault, flag.DefValue)
+		})
+	}
+}
+
+func TestCommandNoConfigIsUnknown(t *testing.T) {
+	cmd := healthcheck.NewCommand(nil, nil)
+	cmd.SetArgs([]string{})
+
+	err := cmd.Execute()
+
+	var ee *healthcheck.ExitError
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, 3, ee.ExitCode())
+}
+
+type fakeClient struct {
+	fail bool
+}
+
+func (f *fakeClient) Call(method string, args any, reply any) error {
+	if f.fail {
+		return errors.New("connection refused")
+	}
+
+	switch method {
+	case "informer.List":
+		*reply.(*[]string) = []string{"http", "jobs"}
+	case "informer.Workers":
+		if args.(string) == "http" {
+			*reply.(**informer.WorkerList) = &informer.WorkerList{Workers: []*process.State{
+				{Pid: 1, Status: "ready", MemoryUsage: 10 << 20},
+				{Pid: 2, Status: "working", MemoryUsage: 300 << 20},
+				{Pid: 3, Status: "invalid", MemoryUsage: 1 << 20},
+			}}
+		}
+	case "informer.Jobs":
+		if args.(string) == "jobs" {
+			*reply.(*[]*jobs.State) = []*jobs.State{{Pipeline: "emails", Active: 5, Delayed: 10}}
+		}
+	case "status.Ready":
+		reply.(*status.Status).Code = 503
+	case "status.Status":
+		reply.(*status.Status).Code = 200
+	}
+
+	return nil
+}
+
+func TestRun_Perf(t *testing.T) {
+	r := healthcheck.Run(&fakeClient{}, &healthcheck.Thresholds{MinReadyWorkers: map[string]int{"http": 3}})
+
+	// lower bound thresholds
+	assert.Contains(t, r.Nagios(), "| http_ready_workers=2;3:;1:")
+}
+
+func TestRun(t *testing.T) {
+	type th = healthcheck.Thresholds
+
+	cases := []struct {
+		name string
+		th   *th
+		fail bool
+		want healthcheck.Status
+	}{
+		{name: "rpc only", th: &th{}, want: healthcheck.OK},
+		{name: "rpc down", th: &th{}, fail: true, want: healthcheck.Critical},
+		{name: "health", th: &th{Health: []string{"http"}}, want: healthcheck.OK},
+		{name: "not ready", th: &th{Ready: []string{"http"}}, want: healthcheck.Critical},
+		{name: "min workers ok", th: &th{MinReadyWorkers: map[string]int{"http": 2}}, want: healthcheck.OK},
+		{name: "min workers warn", th: &th{MinReadyWorkers: map[string]int{"http": 3}}, want: healthcheck.Warning},
+		{name: "memory", th: &th{MaxWorkerMemory: map[string]uint64{"*": 100 << 20}}, want: healthcheck.Critical},
+		{name: "backlog ok", th: &th{MaxJobsBacklog: map[string]int64{"emails": 15}}, want: healthcheck.OK},
+		{name: "backlog", th: &th{MaxJobsBacklog: map[string]int64{"*": 14}}, want: healthcheck.Critical},
+		{name: "plugin missing", th: &th{MinReadyWorkers: map[string]int{"grpc": 1}}, want: healthcheck.Critical},
+		{name: "plugin missing mem", th: &th{MaxWorkerMemory: map[string]uint64{"grpc": 1}}, want: healthcheck.Critical},
+		{name: "pipeline missing", th: &th{MaxJobsBacklog: map[string]int64{"foo": 10}}, want: healthcheck.Critical},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := healthcheck.Run(&fakeClient{fail: tt.fail}, tt.th)
+
+			assert.Equal(t, tt.want, r.Status)
+			assert.Contains(t, r.Nagios(), "RR "+tt.want.String()+" - ")
+
+			js, err := r.JSON()
+			require.NoError(t, err)
+			assert.Contains(t, js, `"status": "`+tt.want.String()+`"`)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCommand creates `http` command.
+func NewCommand(cfgFile *string, override *[]string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "http",
+		Short: "HTTP plugin tools",
+	}
+
+	cmd.AddCommand(newReplayCommand(cfgFile, override))
+
+	return cmd
+}
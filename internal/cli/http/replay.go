@@ -0,0 +1,447 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	netHttp "net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/har"
+	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+
+	configImpl "github.com/roadrunner-server/config/v2"
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+// skipHeaders are not replayed: set by the client or hop-by-hop.
+var skipHeaders = map[string]struct{}{ //nolint:gochecknoglobals
+	"host":              {},
+	"content-length":    {},
+	"connection":        {},
+	"keep-alive":        {},
+	"transfer-encoding": {},
+	"upgrade":           {},
+	"te":                {},
+	"trailer":           {},
+	"accept-encoding":   {},
+}
+
+func newReplayCommand(cfgFile *string, override *[]string) *cobra.Command {
+	r := &replayer{}
+
+	var (
+		target  string
+		header  []string
+		query   []string
+		timeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay <file.har>...",
+		Short: "Re-send the captured HAR traffic and diff the responses with the captured ones",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			const op = errors.Op("rr_http_replay")
+
+			if r.speed < 0 {
+				return errors.E(op, errors.Str("speed should not be negative"))
+			}
+
+			var err error
+			if target == "" {
+				if target, err = configAddress(cfgFile, *override); err != nil {
+					return errors.E(op, err)
+				}
+			}
+
+			if !strings.Contains(target, "://") {
+				target = "http://" + target
+			}
+
+			if r.target, err = url.Parse(target); err != nil {
+				return errors.E(op, err)
+			}
+
+			r.header = netHttp.Header{}
+			for _, h := range header {
+				k, v, ok := strings.Cut(h, ":")
+				if !ok {
+					return errors.E(op, fmt.Errorf("invalid header `%s` (Name: value)", h))
+				}
+
+				r.header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+			}
+
+			r.query = url.Values{}
+			for _, q := range query {
+				k, v, ok := strings.Cut(q, "=")
+				if !ok {
+					return errors.E(op, fmt.Errorf("invalid query parameter `%s` (name=value)", q))
+				}
+
+				r.query.Add(k, v)
+			}
+
+			entries, err := readEntries(args)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			r.client = &netHttp.Client{
+				Timeout: timeout,
+				// redirects are compared as they were captured
+				CheckRedirect: func(*netHttp.Request, []*netHttp.Request) error { return netHttp.ErrUseLastResponse },
+			}
+			r.out = cmd.OutOrStdout()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			diffs := r.run(ctx, entries)
+			if diffs > 0 {
+				return errors.E(op, fmt.Errorf("%d of %d responses differ", diffs, len(entries)))
+			}
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&target, "target", "", "address to replay to (default: http.address from the configuration)")
+	f.Float64Var(&r.speed, "speed", 1,
+		"timing: 1 preserves the captured intervals, 10 compresses them 10 times, 0 sends one by one without delays")
+	f.StringArrayVarP(&header, "header", "H", nil, "set the request header, e.g. to replace redacted ones (Name: value)")
+	f.StringArrayVar(&query, "query", nil, "set the query parameter, e.g. to replace redacted ones (name=value)")
+	f.BoolVar(&r.ignoreBody, "ignore-body", false, "compare the status codes only")
+	f.DurationVar(&timeout, "timeout", time.Second*30, "request timeout")
+
+	return cmd
+}
+
+// configAddress returns http.address from the configuration.
+func configAddress(cfgFile *string, override []string) (string, error) {
+	if cfgFile == nil {
+		return "", errors.Str("no configuration file provided, use --target")
+	}
+
+	cfg := &configImpl.Plugin{Path: *cfgFile, Prefix: "rr", Flags: override, Version: meta.Version()}
+	if err := cfg.Init(); err != nil {
+		return "", fmt.Errorf("%w (use --target)", err)
+	}
+
+	addr, _ := cfg.Get("http.address").(string)
+	if addr == "" {
+		return "", errors.Str("http.address is not configured, use --target")
+	}
+
+	return addr, nil
+}
+
+// readEntries returns the entries of the HAR files in the captured order. The files may overlap or be passed in any
+// order, and the replay offsets are counted from the first captured entry.
+func readEntries(files []string) ([]*har.Entry, error) {
+	var entries []*har.Entry
+
+	for _, f := range files {
+		h, err := har.Read(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+
+		entries = append(entries, h.Log.Entries...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	return entries, nil
+}
+
+// replayer re-sends the captured requests.
+type replayer struct {
+	target     *url.URL
+	header     netHttp.Header
+	query      url.Values
+	speed      float64
+	ignoreBody bool
+	client     *netHttp.Client
+	out        io.Writer
+}
+
+// result of the replayed request.
+type result struct {
+	status int
+	body   []byte
+	err    error
+	// why the request was not replayed
+	skipped string
+}
+
+// run replays the entries and prints the diff, returns the number of differing responses.
+func (r *replayer) run(ctx context.Context, entries []*har.Entry) int {
+	results := make([]*result, len(entries))
+
+	if r.speed == 0 {
+		for i, e := range entries {
+			if reason := notReplayable(e); reason != "" {
+				results[i] = &result{skipped: reason}
+
+				continue
+			}
+
+			results[i] = r.send(ctx, e)
+		}
+	} else {
+		var wg sync.WaitGroup
+
+		start := time.Now()
+
+	schedule:
+		for i, e := range entries {
+			if reason := notReplayable(e); reason != "" {
+				results[i] = &result{skipped: reason}
+
+				continue
+			}
+
+			offset := time.Duration(float64(e.StartedDateTime.Sub(entries[0].StartedDateTime)) / r.speed)
+
+			select {
+			case <-ctx.Done():
+				break schedule
+			case <-time.After(time.Until(start.Add(offset))):
+			}
+
+			wg.Add(1)
+
+			go func(i int, e *har.Entry) {
+				defer wg.Done()
+
+				results[i] = r.send(ctx, e)
+			}(i, e)
+		}
+
+		wg.Wait()
+	}
+
+	diffs, skipped := 0, 0
+
+	for i, e := range entries {
+		res := results[i]
+		if res == nil {
+			res = &result{err: errors.Str("not sent")}
+		}
+
+		if res.skipped != "" {
+			skipped++
+
+			_, _ = fmt.Fprintf(r.out, "#%d %s %s: SKIP not replayable, %s\n", i+1, e.Request.Method,
+				pathOf(e.Request.URL), res.skipped)
+
+			continue
+		}
+
+		diff := r.diff(e, res)
+		if diff != "" {
+			diffs++
+		}
+
+		line := fmt.Sprintf("#%d %s %s: %d", i+1, e.Request.Method, pathOf(e.Request.URL), res.status)
+		if res.err != nil {
+			_, _ = fmt.Fprintf(r.out, "#%d %s %s: ERROR %s\n", i+1, e.Request.Method, pathOf(e.Request.URL), diff)
+
+			continue
+		}
+
+		if diff == "" {
+			_, _ = fmt.Fprintf(r.out, "%s OK\n", line)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(r.out, "%s DIFF %s\n", line, diff)
+	}
+
+	_, _ = fmt.Fprintf(r.out, "\nreplayed %d requests, %d differ, %d not replayable\n", len(entries)-skipped, diffs,
+		skipped)
+
+	return diffs
+}
+
+func (r *replayer) send(ctx context.Context, e *har.Entry) *result {
+	u, err := url.Parse(e.Request.URL)
+	if err != nil {
+		return &result{err: err}
+	}
+
+	u.Scheme, u.Host = r.target.Scheme, r.target.Host
+	r.setQuery(u)
+
+	var body []byte
+	if e.Request.PostData != nil {
+		if body, err = e.Request.PostData.Body(); err != nil {
+			return &result{err: err}
+		}
+	}
+
+	req, err := netHttp.NewRequestWithContext(ctx, e.Request.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return &result{err: err}
+	}
+
+	for _, h := range e.Request.Headers {
+		if _, skip := skipHeaders[strings.ToLower(h.Name)]; skip || h.Value == har.Redacted {
+			continue
+		}
+
+		req.Header.Add(h.Name, h.Value)
+	}
+
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+
+	rsp, err := r.client.Do(req)
+	if err != nil {
+		return &result{err: err}
+	}
+
+	defer func() { _ = rsp.Body.Close() }()
+
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return &result{status: rsp.StatusCode, err: err}
+	}
+
+	return &result{status: rsp.StatusCode, body: data}
+}
+
+// notReplayable returns why the captured request can't be replayed, empty when it can.
+func notReplayable(e *har.Entry) string {
+	if e.Request.PostData != nil && e.Request.PostData.Truncated {
+		return "the request body was truncated on capture"
+	}
+
+	return ""
+}
+
+// setQuery drops the redacted query values, as the redacted headers are dropped, and sets the --query parameters. The
+// query is re-encoded only when it changes.
+func (r *replayer) setQuery(u *url.URL) {
+	query := u.Query()
+	changed := false
+
+	for name, values := range query {
+		kept := make([]string, 0, len(values))
+		for _, v := range values {
+			if v != har.Redacted {
+				kept = append(kept, v)
+			}
+		}
+
+		if len(kept) == len(values) {
+			continue
+		}
+
+		changed = true
+
+		if len(kept) == 0 {
+			delete(query, name)
+
+			continue
+		}
+
+		query[name] = kept
+	}
+
+	for name, values := range r.query {
+		query[name] = values
+		changed = true
+	}
+
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+}
+
+// diff describes the difference between the captured and the replayed responses, empty when they are equal.
+func (r *replayer) diff(e *har.Entry, res *result) string {
+	if res.err != nil {
+		return res.err.Error()
+	}
+
+	if res.status != e.Response.Status {
+		return fmt.Sprintf("status: captured %d", e.Response.Status)
+	}
+
+	if r.ignoreBody || e.Response.Content == nil {
+		return ""
+	}
+
+	captured, err := e.Response.Content.Body()
+	if err != nil {
+		return fmt.Sprintf("captured body: %v", err)
+	}
+
+	got := res.body
+	if e.Response.Content.Truncated && len(got) > len(captured) {
+		// only the captured prefix is compared
+		got = got[:len(captured)]
+	} else if !e.Response.Content.Truncated && len(got) != len(captured) {
+		return fmt.Sprintf("body: %d bytes, captured %d bytes%s", len(res.body), len(captured), firstDiff(captured, got))
+	}
+
+	if !bytes.Equal(got, captured) {
+		return "body" + firstDiff(captured, got)
+	}
+
+	return ""
+}
+
+// firstDiff describes the first differing byte with the context around it.
+func firstDiff(captured, got []byte) string {
+	i := 0
+	for i < len(captured) && i < len(got) && captured[i] == got[i] {
+		i++
+	}
+
+	from := i - 20
+	if from < 0 {
+		from = 0
+	}
+
+	snippet := func(b []byte) string {
+		to := i + 20
+		if to > len(b) {
+			to = len(b)
+		}
+
+		if from >= to {
+			return `""`
+		}
+
+		return fmt.Sprintf("%q", b[from:to])
+	}
+
+	return fmt.Sprintf(", differs at byte %d: %s, captured %s", i, snippet(got), snippet(captured))
+}
+
+func pathOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return u.RequestURI()
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	netHttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/har"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntry(started time.Time, method, path, body string, status int, rsp string, truncated bool) *har.Entry {
+	e := &har.Entry{
+		StartedDateTime: started,
+		Request: &har.Request{
+			Method: method,
+			URL:    "https://prod.example.com" + path,
+			Headers: []har.NameValue{
+				{Name: "Authorization", Value: har.Redacted},
+				{Name: "X-Trace", Value: "1"},
+				{Name: "Host", Value: "prod.example.com"},
+			},
+		},
+		Response: &har.Response{
+			Status:  status,
+			Content: &har.Content{Text: rsp, Truncated: truncated},
+		},
+	}
+
+	if body != "" {
+		e.Request.PostData = &har.PostData{Text: body}
+	}
+
+	return e
+}
+
+func TestReplayer_Run(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		headers []netHttp.Header
+	)
+
+	srv := httptest.NewServer(netHttp.HandlerFunc(func(w netHttp.ResponseWriter, r *netHttp.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(netHttp.StatusNotFound)
+		}
+
+		data, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte(r.Method+" "+r.URL.RequestURI()+" "), data...))
+	}))
+	defer srv.Close()
+
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	now := time.Now()
+	entries := []*har.Entry{
+		testEntry(now, "GET", "/a?x=1", "", 200, "GET /a?x=1 ", false),
+		testEntry(now.Add(time.Millisecond*200), "POST", "/b", "data", 200, "POST /b data", false),
+		// captured body is truncated, the prefix is compared
+		testEntry(now.Add(time.Millisecond*400), "POST", "/c", "long", 200, "POST /c lo", true),
+		testEntry(now.Add(time.Millisecond*600), "GET", "/missing", "", 200, "", false),
+		testEntry(now.Add(time.Millisecond*800), "GET", "/d", "", 200, "GET /d changed", false),
+		// redacted query values are dropped
+		testEntry(now.Add(time.Millisecond*800), "GET", "/e?token=%5BREDACTED%5D&x=1", "", 200, "GET /e?x=1 ", false),
+		// captured request body is truncated, the request is not sent
+		testEntry(now.Add(time.Millisecond*800), "POST", "/f", "long", 200, "", false),
+	}
+
+	entries[6].Request.PostData.Truncated = true
+
+	out := &bytes.Buffer{}
+	r := &replayer{
+		target: target,
+		header: netHttp.Header{"Authorization": {"Bearer local"}},
+		speed:  10,
+		client: &netHttp.Client{Timeout: time.Second},
+		out:    out,
+	}
+
+	start := time.Now()
+	assert.Equal(t, 2, r.run(context.Background(), entries))
+
+	// 800ms compressed 10 times
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, time.Millisecond*80)
+	assert.Less(t, elapsed, time.Millisecond*700)
+
+	assert.Contains(t, out.String(), "#1 GET /a?x=1: 200 OK\n")
+	assert.Contains(t, out.String(), "#3 POST /c: 200 OK\n")
+	assert.Contains(t, out.String(), "#4 GET /missing: 404 DIFF status: captured 200\n")
+	assert.Contains(t, out.String(), "#5 GET /d: 200 DIFF body: 7 bytes, captured 14 bytes")
+	assert.Contains(t, out.String(), "#6 GET /e?token=%5BREDACTED%5D&x=1: 200 OK\n")
+	assert.Contains(t, out.String(), "#7 POST /f: SKIP not replayable, the request body was truncated on capture\n")
+	assert.Contains(t, out.String(), "replayed 6 requests, 2 differ, 1 not replayable")
+
+	require.Len(t, headers, 6)
+	assert.Equal(t, "Bearer local", headers[0].Get("Authorization"))
+	assert.Equal(t, "1", headers[0].Get("X-Trace"))
+
+	// sequential replay, statuses only
+	out.Reset()
+	r.speed, r.ignoreBody, r.header = 0, true, netHttp.Header{}
+	assert.Equal(t, 1, r.run(context.Background(), entries))
+	assert.Empty(t, headers[len(headers)-1].Get("Authorization"))
+
+	// --query replaces the redacted value
+	out.Reset()
+	r.query, r.ignoreBody = url.Values{"token": {"local"}}, false
+	r.run(context.Background(), entries[5:6])
+	assert.Contains(t, out.String(), "#1 GET /e?token=%5BREDACTED%5D&x=1: 200 DIFF")
+}
+
+func TestReadEntries(t *testing.T) {
+	now := time.Now().UTC()
+	dir := t.TempDir()
+
+	write := func(name string, entries ...*har.Entry) string {
+		data, err := json.Marshal(&har.HAR{Log: &har.Log{Version: "1.2", Entries: entries}})
+		require.NoError(t, err)
+
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		return path
+	}
+
+	// the newer file is passed first
+	second := write("second.har", testEntry(now.Add(time.Second*2), "GET", "/c", "", 200, "", false))
+	first := write("first.har",
+		testEntry(now, "GET", "/a", "", 200, "", false),
+		testEntry(now.Add(time.Second*3), "GET", "/d", "", 200, "", false),
+		testEntry(now.Add(time.Second), "GET", "/b", "", 200, "", false),
+	)
+
+	entries, err := readEntries([]string{second, first})
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, pathOf(e.Request.URL))
+	}
+
+	assert.Equal(t, []string{"/a", "/b", "/c", "/d"}, paths)
+}
+
+func TestFirstDiff(t *testing.T) {
+	assert.Equal(t, `, differs at byte 3: "abcX", captured "abcd"`, firstDiff([]byte("abcd"), []byte("abcX")))
+	assert.Equal(t, `, differs at byte 0: "", captured "a"`, firstDiff([]byte("a"), nil))
+}
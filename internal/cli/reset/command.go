@@ -1,7 +1,10 @@
 package reset
 
 import (
+	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"sync"
 
 	internalRpc "github.com/roadrunner-server/roadrunner/v2/internal/rpc"
@@ -10,17 +13,42 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	resetterList  string = "resetter.List"
+	resetterReset string = "resetter.Reset"
+)
+
 // NewCommand creates `reset` command.
-func NewCommand(cfgFile *string, override *[]string, silent *bool) *cobra.Command {
-	return &cobra.Command{
+func NewCommand(cfgFile *string, override *[]string, silent *bool) *cobra.Command { //nolint:funlen
+	var (
+		// rpc addresses of the fleet instances
+		rpcTargets []string
+		// path to the fleet inventory file
+		inventory string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "reset",
 		Short: "Reset workers of all or specific RoadRunner service",
 		RunE: func(_ *cobra.Command, args []string) error {
-			const (
-				op            = errors.Op("reset_handler")
-				resetterList  = "resetter.List"
-				resetterReset = "resetter.Reset"
-			)
+			const op = errors.Op("reset_handler")
+
+			instances, err := internalRpc.Targets(rpcTargets, inventory)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			if len(instances) > 0 {
+				reset := func(inst internalRpc.Instance, client internalRpc.Caller) error {
+					return resetPlugins(inst.Name, args, client, *silent)
+				}
+
+				errs := internalRpc.Each(instances, internalRpc.CallTimeout, reset)
+
+				return internalRpc.Summarize(instances, errs, func(inst internalRpc.Instance, err error) {
+					log.Printf("[%s] reset failed: %v", inst.Name, err)
+				})
+			}
 
 			if cfgFile == nil {
 				return errors.E(op, errors.Str("no configuration file provided"))
@@ -33,42 +61,81 @@ func NewCommand(cfgFile *string, override *[]string, silent *bool) *cobra.Comman
 
 			defer func() { _ = client.Close() }()
 
-			plugins := args        // by default we expect services list from user
-			if len(plugins) == 0 { // but if nothing was passed - request all services list
-				if err = client.Call(resetterList, true, &plugins); err != nil {
-					return err
-				}
-			}
+			return resetPlugins("", args, client, *silent)
+		},
+	}
 
-			var wg sync.WaitGroup
-			wg.Add(len(plugins))
+	cmd.Flags().StringArrayVarP(
+		&rpcTargets,
+		"rpc",
+		"",
+		nil,
+		"RPC address of the instance (name=tcp://host:6001), may be repeated to reset many instances at once",
+	)
+
+	cmd.Flags().StringVarP(
+		&inventory,
+		"inventory",
+		"",
+		"",
+		"path to the inventory file with the named instances to reset",
+	)
+
+	return cmd
+}
 
-			for _, plugin := range plugins {
-				// simulating some work
-				go func(p string) {
-					if !*silent {
-						log.Printf("resetting plugin: [%s] ", p)
-					}
-					defer wg.Done()
+// resetPlugins resets the plugins (all of them if nothing was passed) of the instance. Instance name is used only
+// as a log prefix. Failed resets are returned as a single error.
+func resetPlugins(instance string, plugins []string, client internalRpc.Caller, silent bool) error {
+	prefix := ""
+	if instance != "" {
+		prefix = "[" + instance + "] "
+	}
 
-					var done bool
-					<-client.Go(resetterReset, p, &done, nil).Done
+	if len(plugins) == 0 { // but if nothing was passed - request all services list
+		if err := client.Call(resetterList, true, &plugins); err != nil {
+			return err
+		}
+	}
 
-					if err != nil {
-						log.Println(err)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []string
+	)
 
-						return
-					}
+	wg.Add(len(plugins))
 
-					if !*silent {
-						log.Printf("plugin reset: [%s]", p)
-					}
-				}(plugin)
+	for _, plugin := range plugins {
+		// simulating some work
+		go func(p string) {
+			if !silent {
+				log.Printf("%sresetting plugin: [%s] ", prefix, p)
 			}
+			defer wg.Done()
 
-			wg.Wait()
+			var done bool
+			if err := client.Call(resetterReset, p, &done); err != nil {
+				mu.Lock()
+				failed = append(failed, fmt.Sprintf("[%s]: %v", p, err))
+				mu.Unlock()
 
-			return nil
-		},
+				return
+			}
+
+			if !silent {
+				log.Printf("%splugin reset: [%s]", prefix, p)
+			}
+		}(plugin)
+	}
+
+	wg.Wait()
+
+	if len(failed) == 0 {
+		return nil
 	}
+
+	sort.Strings(failed)
+
+	return fmt.Errorf("failed to reset %d of %d plugins: %s", len(failed), len(plugins), strings.Join(failed, "; "))
 }
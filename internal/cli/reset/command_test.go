@@ -1,13 +1,65 @@
 package reset_test
 
 import (
+	"bytes"
+	"errors"
+	"log"
+	"net"
+	"net/rpc"
+	"os"
 	"testing"
 
 	"github.com/roadrunner-server/roadrunner/v2/internal/cli/reset"
 
+	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// resetter is the fake resetter plugin RPC, failing the reset of the listed plugins.
+type resetter struct {
+	fail map[string]bool
+}
+
+func (r *resetter) List(_ bool, list *[]string) error {
+	*list = []string{"http", "jobs"}
+
+	return nil
+}
+
+func (r *resetter) Reset(plugin string, done *bool) error {
+	if r.fail[plugin] {
+		return errors.New("pool is stopped")
+	}
+
+	*done = true
+
+	return nil
+}
+
+func serve(t *testing.T, r *resetter) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = l.Close() })
+
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("resetter", r))
+
+	go func() {
+		for {
+			conn, errA := l.Accept()
+			if errA != nil {
+				return
+			}
+
+			go srv.ServeCodec(goridgeRpc.NewCodec(conn))
+		}
+	}()
+
+	return "tcp://" + l.Addr().String()
+}
+
 func TestCommandProperties(t *testing.T) {
 	path := ""
 	f := false
@@ -17,6 +69,36 @@ func TestCommandProperties(t *testing.T) {
 	assert.NotNil(t, cmd.RunE)
 }
 
-func TestExecution(t *testing.T) {
-	t.Skip("Command execution is not implemented yet")
+func TestExecution_PartialFailure(t *testing.T) {
+	ok := serve(t, &resetter{})
+	failing := serve(t, &resetter{fail: map[string]bool{"jobs": true}})
+
+	path := ""
+	silent := true
+
+	// every plugin of every instance is reset
+	cmd := reset.NewCommand(&path, &[]string{}, &silent)
+	cmd.SetArgs([]string{"--rpc", "web-1=" + ok})
+	assert.NoError(t, cmd.Execute())
+
+	// one failed plugin of one instance fails the whole run
+	cmd = reset.NewCommand(&path, &[]string{}, &silent)
+	cmd.SetArgs([]string{"--rpc", "web-1=" + ok, "--rpc", "web-2=" + failing})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 instances failed")
+
+	// the failed plugins are reported per instance
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	cmd = reset.NewCommand(&path, &[]string{}, &silent)
+	cmd.SetArgs([]string{"--rpc", "web-2=" + failing, "http", "jobs"})
+
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "[web-2] reset failed: failed to reset 1 of 2 plugins: [jobs]: pool is stopped")
 }
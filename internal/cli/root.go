@@ -8,12 +8,24 @@ import (
 	"strconv"
 
 	"github.com/roadrunner-server/errors"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/bench"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/chaos"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/config"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/dev"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/env"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/exec"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/healthcheck"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/http"
 	"github.com/roadrunner-server/roadrunner/v2/internal/cli/reset"
 	"github.com/roadrunner-server/roadrunner/v2/internal/cli/serve"
 	"github.com/roadrunner-server/roadrunner/v2/internal/cli/stop"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/upgrade"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/workerprobe"
 	"github.com/roadrunner-server/roadrunner/v2/internal/cli/workers"
-	dbg "github.com/roadrunner-server/roadrunner/v2/internal/debug"
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/workerstub"
+	"github.com/roadrunner-server/roadrunner/v2/internal/envconfig"
 	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+	"github.com/roadrunner-server/roadrunner/v2/pkg/worker"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
@@ -43,7 +55,11 @@ func NewCommand(cmdName string) *cobra.Command { //nolint:funlen,gocognit
 	// path to the .env file
 	var dotenv string
 	// debug mode
-	var debug bool
+	debug := toPtr(false)
+	// debug server address
+	debugAddr := toPtr("")
+	// temporary configuration file built from the environment
+	envFile := toPtr("")
 
 	cmd := &cobra.Command{
 		Use:           cmdName,
@@ -51,7 +67,7 @@ func NewCommand(cmdName string) *cobra.Command { //nolint:funlen,gocognit
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		Version:       fmt.Sprintf("%s (build time: %s, %s), OS: %s, arch: %s", meta.Version(), meta.BuildTime(), runtime.Version(), runtime.GOOS, runtime.GOARCH),
-		PersistentPreRunE: func(*cobra.Command, []string) error {
+		PersistentPreRunE: func(c *cobra.Command, _ []string) error {
 			// cfgFile could be defined by user or default `.rr.yaml`
 			// this check added just to be safe
 			if cfgFile == nil || *cfgFile == "" {
@@ -88,9 +104,17 @@ func NewCommand(cmdName string) *cobra.Command { //nolint:funlen,gocognit
 				}
 			}
 
-			if debug {
-				srv := dbg.NewServer()
-				go func() { _ = srv.Start(":6061") }() // TODO implement graceful server stopping
+			// no configuration file, build it from the RR_ environment variables (workers inherit them, skipped there)
+			_, errS := os.Stat(*cfgFile)
+			if os.IsNotExist(errS) && !c.Flags().Changed("config") && os.Getenv(worker.EnvMode) == "" {
+				path, errE := fromEnv(c, *silent)
+				if errE != nil {
+					return errE
+				}
+
+				if path != "" {
+					*cfgFile, *envFile = path, path
+				}
 			}
 
 			// user wanted to write a .pid file
@@ -109,6 +133,21 @@ func NewCommand(cmdName string) *cobra.Command { //nolint:funlen,gocognit
 				}
 			}
 
+			return nil
+		},
+		// not called when the command fails, the caller calls it then (see cmd/rr)
+		PersistentPostRunE: func(*cobra.Command, []string) error {
+			if *envFile == "" {
+				return nil
+			}
+
+			err := os.Remove(*envFile)
+			*envFile = ""
+
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+
 			return nil
 		},
 	}
@@ -120,20 +159,60 @@ func NewCommand(cmdName string) *cobra.Command { //nolint:funlen,gocognit
 	f.StringVarP(cfgFile, "config", "c", ".rr.yaml", "config file")
 	f.StringVarP(&workDir, "WorkDir", "w", "", "working directory")
 	f.StringVarP(&dotenv, "dotenv", "", "", fmt.Sprintf("dotenv file [$%s]", envDotenv))
-	f.BoolVarP(&debug, "debug", "d", false, "debug mode")
+	f.BoolVarP(debug, "debug", "d", false, "debug mode")
+	f.StringVarP(debugAddr, "debug-addr", "", "", "debug server address (overrides debug.address, default 127.0.0.1:6061)")
 	f.BoolVarP(silent, "silent", "s", false, "print startup message")
 	f.StringArrayVarP(override, "override", "o", nil, "override config value (dot.notation=value)")
 
 	cmd.AddCommand(
 		workers.NewCommand(cfgFile, override),
 		reset.NewCommand(cfgFile, override, silent),
-		serve.NewCommand(override, cfgFile, silent),
+		serve.NewCommand(override, cfgFile, silent, debug, debugAddr),
 		stop.NewCommand(silent, forceStop),
+		healthcheck.NewCommand(cfgFile, override),
+		upgrade.NewCommand(cfgFile, override, silent),
+		workerstub.NewCommand(),
+		workerprobe.NewCommand(cfgFile, override),
+		bench.NewCommand(cfgFile, override),
+		http.NewCommand(cfgFile, override),
+		chaos.NewCommand(cfgFile, override),
+		dev.NewCommand(cfgFile, override, silent, debugAddr),
+		exec.NewCommand(cfgFile, override),
+		env.NewCommand(cfgFile, override, &dotenv),
+		config.NewCommand(),
 	)
 
 	return cmd
 }
 
+// fromEnv writes the configuration built from the environment into the temporary file removed by the persistent post
+// run. Empty path is returned when there are no configuration variables.
+func fromEnv(cmd *cobra.Command, silent bool) (string, error) {
+	const op = errors.Op("rr_env_config")
+
+	values, unknown, err := envconfig.Build(os.Environ())
+	if err != nil {
+		return "", errors.E(op, err)
+	}
+
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	if !silent {
+		for _, name := range unknown {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "unknown configuration variable %s (see `rr config env`)\n", name)
+		}
+	}
+
+	path, err := envconfig.WriteTemp(values)
+	if err != nil {
+		return "", errors.E(op, err)
+	}
+
+	return path, nil
+}
+
 func toPtr[T any](val T) *T {
 	return &val
 }
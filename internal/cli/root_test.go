@@ -1,8 +1,10 @@
 package cli_test
 
 import (
+	"errors"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 
 	"github.com/roadrunner-server/roadrunner/v2/internal/cli"
@@ -21,6 +23,17 @@ func TestCommandSubcommands(t *testing.T) {
 		{giveName: "workers"},
 		{giveName: "reset"},
 		{giveName: "serve"},
+		{giveName: "healthcheck"},
+		{giveName: "upgrade"},
+		{giveName: "worker-stub"},
+		{giveName: "worker-probe"},
+		{giveName: "bench"},
+		{giveName: "http"},
+		{giveName: "chaos"},
+		{giveName: "dev"},
+		{giveName: "exec"},
+		{giveName: "env"},
+		{giveName: "config"},
 	}
 
 	// get all existing subcommands and put into the map
@@ -51,6 +64,7 @@ func TestCommandFlags(t *testing.T) {
 		{giveName: "WorkDir", wantShorthand: "w", wantDefault: ""},
 		{giveName: "dotenv", wantShorthand: "", wantDefault: ""},
 		{giveName: "debug", wantShorthand: "d", wantDefault: "false"},
+		{giveName: "debug-addr", wantShorthand: "", wantDefault: ""},
 		{giveName: "override", wantShorthand: "o", wantDefault: "[]"},
 	}
 
@@ -71,6 +85,18 @@ func TestCommandFlags(t *testing.T) {
 	}
 }
 
+func TestCommandSubcommandFlags(t *testing.T) {
+	cmd := cli.NewCommand("unit test")
+
+	// subcommand flags should not clash with the persistent flags (cobra panics on the execution)
+	for _, sub := range cmd.Commands() {
+		sub := sub
+		t.Run(sub.Name(), func(t *testing.T) {
+			assert.NotPanics(t, func() { sub.InheritedFlags() })
+		})
+	}
+}
+
 func TestCommandSimpleExecuting(t *testing.T) {
 	cmd := cli.NewCommand("unit test")
 	cmd.SetArgs([]string{"-c", "./../../.rr.yaml"})
@@ -165,3 +191,54 @@ func TestCommandWorkingDir(t *testing.T) {
 		_ = os.RemoveAll(path.Join(tmp, ".rr.yaml"))
 	})
 }
+
+func TestCommandEnvConfig(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("RR_HTTP_ADDRESS", "127.0.0.1:8080")
+	t.Setenv("TMPDIR", t.TempDir())
+
+	cmd := cli.NewCommand("unit test")
+	cmd.SetArgs([]string{"-w", tmp})
+
+	var data []byte
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		files, _ := filepath.Glob(filepath.Join(os.TempDir(), "rr-env-*.yaml"))
+		if assert.Len(t, files, 1) {
+			data, _ = os.ReadFile(files[0])
+		}
+	}
+
+	assert.NoError(t, cmd.Execute())
+	assert.Contains(t, string(data), "address: 127.0.0.1:8080")
+
+	// the configuration file is removed after the execution
+	files, err := filepath.Glob(filepath.Join(os.TempDir(), "rr-env-*.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestCommandEnvConfigFailed(t *testing.T) {
+	t.Setenv("RR_HTTP_ADDRESS", "127.0.0.1:8080")
+	t.Setenv("TMPDIR", t.TempDir())
+
+	cmd := cli.NewCommand("unit test")
+	cmd.SetArgs([]string{"-w", t.TempDir()})
+
+	cmd.RunE = func(*cobra.Command, []string) error {
+		return errors.New("failed")
+	}
+
+	require.Error(t, cmd.Execute())
+
+	// the post run is skipped, the caller removes the file
+	files, err := filepath.Glob(filepath.Join(os.TempDir(), "rr-env-*.yaml"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	require.NoError(t, cmd.PersistentPostRunE(cmd, nil))
+
+	files, err = filepath.Glob(filepath.Join(os.TempDir(), "rr-env-*.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -1,26 +1,70 @@
 package serve
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
-	"github.com/roadrunner-server/roadrunner/v2/internal/container"
+	"github.com/roadrunner-server/roadrunner/v2/internal/activation"
+	dbg "github.com/roadrunner-server/roadrunner/v2/internal/debug"
 	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+	"github.com/roadrunner-server/roadrunner/v2/internal/sdnotify"
+	"github.com/roadrunner-server/roadrunner/v2/internal/upgrade"
 
-	configImpl "github.com/roadrunner-server/config/v2"
+	endure "github.com/roadrunner-server/endure/pkg/container"
 	"github.com/roadrunner-server/errors"
+	"github.com/roadrunner-server/informer/v2"
 	"github.com/spf13/cobra"
 )
 
 const (
 	rrPrefix string = "rr"
+	// time for the upgraded process to init and serve its container
+	upgradeTimeout = time.Minute
 )
 
+// server holds the running endure container, which is replaced on the configuration reload.
+type server struct {
+	cfgFile  string
+	override []string
+	// plugins selection
+	only    []string
+	exclude []string
+
+	container *endure.Endure
+	errCh     <-chan *endure.Result
+	running   *snapshot
+	informer  *informer.Plugin
+	diag      *diagnostics
+	// socket activated listeners, nil when not activated
+	fds *activation.Bridge
+	// binary upgrade requests (`upgrade.Upgrade` RPC)
+	upgrades chan struct{}
+	// last time the container was alive, gates the systemd watchdog pings
+	alive time.Time
+}
+
 // NewCommand creates `serve` command.
-func NewCommand(override *[]string, cfgFile *string, silent *bool) *cobra.Command { //nolint:funlen
-	return &cobra.Command{
+func NewCommand( //nolint:funlen,gocognit,gocyclo
+	override *[]string, cfgFile *string, silent, debug *bool, debugAddr *string,
+) *cobra.Command {
+	var (
+		// reload the configuration on the file change
+		watchCfg bool
+		// restart the container when a plugin fails
+		supervise      bool
+		maxRestarts    int
+		restartWindow  time.Duration
+		restartBackoff time.Duration
+		// register only these plugins or all except excluded
+		only    []string
+		exclude []string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Start RoadRunner server",
 		RunE: func(*cobra.Command, []string) error {
@@ -30,45 +74,57 @@ func NewCommand(override *[]string, cfgFile *string, silent *bool) *cobra.Comman
 				return errors.E(op, errors.Str("no configuration file provided"))
 			}
 
-			// create endure container config
-			containerCfg, err := container.NewConfig(*cfgFile)
+			// read the configuration and create endure container config
+			snap, err := readSnapshot(*cfgFile, *override)
 			if err != nil {
 				return errors.E(op, err)
 			}
 
-			cfg := &configImpl.Plugin{
-				Path:    *cfgFile,
-				Prefix:  rrPrefix,
-				Timeout: containerCfg.GracePeriod,
-				Flags:   *override,
-				Version: meta.Version(),
-			}
-
-			// create endure container
-			endureContainer, err := container.NewContainer(*containerCfg)
+			dbgCfg, err := dbg.NewConfig(*cfgFile)
 			if err != nil {
 				return errors.E(op, err)
 			}
 
-			// register config plugin
-			if err = endureContainer.Register(cfg); err != nil {
+			// start debug server (pprof, expvar, runtime metrics), it is stopped together with the container
+			if debug != nil && *debug {
+				dbgSrv, errD := startDebugServer(dbgCfg, debugAddr, silent != nil && *silent)
+				if errD != nil {
+					return errors.E(op, errD)
+				}
+
+				defer func() {
+					ctx, cancel := context.WithTimeout(context.Background(), snap.containerCfg.GracePeriod)
+					defer cancel()
+
+					_ = dbgSrv.Stop(ctx)
+				}()
+			}
+
+			srv := &server{
+				cfgFile:  *cfgFile,
+				override: *override,
+				only:     only,
+				exclude:  exclude,
+				diag:     &diagnostics{cfg: dbgCfg, version: meta.Version()},
+				upgrades: make(chan struct{}, 1),
+			}
+
+			// sockets passed by systemd or a parent launcher (LISTEN_FDS), referenced as fd://name in the config
+			listeners, err := activation.Listeners()
+			if err != nil {
 				return errors.E(op, err)
 			}
 
-			// register another container plugins
-			for i, plugins := 0, container.Plugins(); i < len(plugins); i++ {
-				if err = endureContainer.Register(plugins[i]); err != nil {
+			if len(listeners) > 0 {
+				if srv.fds, err = activation.NewBridge(listeners); err != nil {
 					return errors.E(op, err)
 				}
-			}
 
-			// init container and all services
-			if err = endureContainer.Init(); err != nil {
-				return errors.E(op, err)
+				defer srv.fds.Stop()
 			}
 
-			// start serving the graph
-			errCh, err := endureContainer.Serve()
+			// create endure container with all plugins and init them
+			endureContainer, pl, err := srv.build(*cfgFile, snap)
 			if err != nil {
 				return errors.E(op, err)
 			}
@@ -89,18 +145,176 @@ func NewCommand(override *[]string, cfgFile *string, silent *bool) *cobra.Comman
 				os.Exit(1)
 			}()
 
+			var sup *supervisor
+			if supervise {
+				sup = newSupervisor(maxRestarts, restartWindow, restartBackoff)
+			}
+
+			// start serving the graph
+			errCh, err := endureContainer.Serve()
+			srv.set(endureContainer, errCh, pl, snap)
+
+			if err != nil {
+				if sup == nil {
+					return errors.E(op, err)
+				}
+
+				// e.g. a broker is not reachable yet
+				stopped, errS := srv.supervise(sup, &endure.Result{Error: err, VertexID: "container"}, stop)
+				if errS != nil {
+					return errS
+				}
+
+				if stopped {
+					return nil
+				}
+			}
+
+			// plugins have bound the bridged sockets, start accepting on the activated ones
+			if srv.fds != nil {
+				srv.fds.Start()
+			}
+
+			// continuous profiling to disk
+			if dbgCfg.Profiling.Interval > 0 {
+				profiler := dbg.NewProfiler(&dbgCfg.Profiling, meta.Version())
+				profiler.Start()
+
+				defer profiler.Stop()
+			}
+
+			// heap and goroutine dumps when RR's own resources thresholds are crossed
+			if dbgCfg.Watchdog.Enabled() {
+				watchdog := dbg.NewWatchdog(&dbgCfg.Watchdog, meta.Version())
+				watchdog.Start()
+
+				defer watchdog.Stop()
+			}
+
+			// goroutine dump, workers reset and execution trace on signals
+			if sigs := diagnosticSignals(); len(sigs) > 0 {
+				dss := make(chan os.Signal, 1)
+				signal.Notify(dss, sigs...)
+
+				go func() {
+					for sig := range dss {
+						srv.diag.handle(sig)
+					}
+				}()
+
+				defer func() {
+					signal.Stop(dss)
+					close(dss)
+				}()
+			}
+
+			// configuration reload on SIGHUP or on the config file change
+			reload := make(chan os.Signal, 1)
+			if sigs := reloadSignals(); len(sigs) > 0 {
+				signal.Notify(reload, sigs...)
+				defer signal.Stop(reload)
+			}
+
+			var changed <-chan struct{}
+			if watchCfg {
+				done := make(chan struct{})
+				defer close(done)
+
+				if changed, err = watchConfig(*cfgFile, done); err != nil {
+					return errors.E(op, err)
+				}
+			}
+
 			if !*silent {
 				fmt.Printf("[INFO] RoadRunner server started; version: %s, buildtime: %s\n", meta.Version(), meta.BuildTime())
 			}
 
+			// let the parent process know that we are serving (if started by the upgrade)
+			if err = upgrade.Ready(); err != nil {
+				fmt.Printf("upgrade: failed to notify the parent process: %v\n", err)
+			}
+
+			// systemd notifications: readiness, workers status and the watchdog pings
+			srv.notifyReady()
+
+			var notifyTick <-chan time.Time
+			interval, watchdog := notifyInterval()
+			if interval > 0 {
+				t := time.NewTicker(interval)
+				defer t.Stop()
+
+				notifyTick = t.C
+			}
+
 			for {
 				select {
-				case e := <-errCh:
-					return fmt.Errorf("error: %w\nplugin: %s", e.Error, e.VertexID)
+				case e := <-srv.errCh:
+					if sup == nil {
+						notify(sdnotify.Stopping)
+
+						return fmt.Errorf("error: %w\nplugin: %s", e.Error, e.VertexID)
+					}
+
+					stopped, errS := srv.supervise(sup, e, stop)
+					if errS != nil {
+						notify(sdnotify.Stopping)
+
+						return errS
+					}
+
+					if stopped {
+						notify(sdnotify.Stopping)
+
+						return nil
+					}
+				case <-notifyTick:
+					srv.notifyStatus(watchdog)
+				case <-reload:
+					if err = srv.reload(); err != nil {
+						return errors.E(op, err)
+					}
+				case <-changed:
+					if err = srv.reload(); err != nil {
+						return errors.E(op, err)
+					}
+				case <-srv.upgrades:
+					// activated descriptors are not passed to the new process, the unit should be restarted instead
+					if srv.fds != nil {
+						fmt.Println("upgrade is not supported with socket activation, keep serving")
+
+						continue
+					}
+
+					if !upgrade.MigratesRequests() {
+						fmt.Println("upgrade: net.ipv4.tcp_migrate_req is off, connections queued on the sockets of " +
+							"this process are reset when it stops")
+					}
+
+					notify(sdnotify.Reloading)
+
+					// the new process sends READY with its own MAINPID
+					p, errU := upgrade.Start(upgradeTimeout)
+					if errU != nil {
+						fmt.Printf("upgrade failed, keep serving: %v\n", errU)
+						srv.notifyReady()
+
+						continue
+					}
+
+					fmt.Printf("upgrade: new process %d is ready, grace timeout is: %0.f seconds\n",
+						p.Pid, srv.running.containerCfg.GracePeriod.Seconds())
+
+					if err = srv.container.Stop(); err != nil {
+						return fmt.Errorf("error: %w", err)
+					}
+
+					return nil
 				case <-stop: // stop the container after first signal
-					fmt.Printf("stop signal received, grace timeout is: %0.f seconds\n", containerCfg.GracePeriod.Seconds())
+					notify(sdnotify.Stopping)
+					fmt.Printf("stop signal received, grace timeout is: %0.f seconds\n",
+						srv.running.containerCfg.GracePeriod.Seconds())
 
-					if err = endureContainer.Stop(); err != nil {
+					if err = srv.container.Stop(); err != nil {
 						return fmt.Errorf("error: %w", err)
 					}
 
@@ -109,4 +323,24 @@ func NewCommand(override *[]string, cfgFile *string, silent *bool) *cobra.Comman
 			}
 		},
 	}
+
+	cmd.Flags().BoolVarP(
+		&watchCfg,
+		"watch-config",
+		"",
+		false,
+		"reload the configuration when the config file changes (SIGHUP always reloads it)",
+	)
+
+	f := cmd.Flags()
+
+	f.BoolVarP(&supervise, "supervise", "", false, "restart the container when a plugin fails instead of exiting")
+	f.IntVarP(&maxRestarts, "max-restarts", "", 5, "supervisor: maximum restarts within the restart window") //nolint:gomnd
+	f.DurationVarP(&restartWindow, "restart-window", "", time.Minute*5, "supervisor: restarts counting window")
+	f.DurationVarP(&restartBackoff, "restart-backoff", "", time.Second, "supervisor: initial restart delay (doubles)")
+
+	f.StringSliceVarP(&only, "only", "", nil, "register only these plugins (e.g. server,logs,rpc,http)")
+	f.StringSliceVarP(&exclude, "exclude", "", nil, "do not register these plugins (e.g. temporal,new_relic)")
+
+	return cmd
 }
@@ -10,19 +10,47 @@ import (
 
 func TestCommandProperties(t *testing.T) {
 	path := ""
-	cmd := serve.NewCommand(nil, &path, nil)
+	cmd := serve.NewCommand(nil, &path, nil, nil, nil)
 
 	assert.Equal(t, "serve", cmd.Use)
 	assert.NotNil(t, cmd.RunE)
 }
 
 func TestCommandNil(t *testing.T) {
-	cmd := serve.NewCommand(nil, nil, nil)
+	cmd := serve.NewCommand(nil, nil, nil, nil, nil)
 
 	assert.Equal(t, "serve", cmd.Use)
 	assert.NotNil(t, cmd.RunE)
 }
 
+func TestCommandFlags(t *testing.T) {
+	cmd := serve.NewCommand(nil, nil, nil, nil, nil)
+
+	cases := []struct {
+		giveName     string
+		wantDefValue string
+	}{
+		{giveName: "watch-config", wantDefValue: "false"},
+		{giveName: "supervise", wantDefValue: "false"},
+		{giveName: "max-restarts", wantDefValue: "5"},
+		{giveName: "restart-window", wantDefValue: "5m0s"},
+		{giveName: "restart-backoff", wantDefValue: "1s"},
+		{giveName: "only", wantDefValue: "[]"},
+		{giveName: "exclude", wantDefValue: "[]"},
+	}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.giveName, func(t *testing.T) {
+			flag := cmd.Flag(tt.giveName)
+
+			if assert.NotNil(t, flag) {
+				assert.Equal(t, tt.wantDefValue, flag.DefValue)
+			}
+		})
+	}
+}
+
 func TestExecution(t *testing.T) {
 	t.Skip("Command execution is not implemented yet")
 }
@@ -0,0 +1,85 @@
+package serve
+
+import (
+	"github.com/roadrunner-server/roadrunner/v2/internal/container"
+	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+	"github.com/roadrunner-server/roadrunner/v2/internal/upgrade"
+
+	configImpl "github.com/roadrunner-server/config/v2"
+	endure "github.com/roadrunner-server/endure/pkg/container"
+	"github.com/roadrunner-server/errors"
+	"github.com/roadrunner-server/informer/v2"
+	"github.com/roadrunner-server/resetter/v2"
+)
+
+// inproc holds container plugins used by the serve command itself, without RPC.
+type inproc struct {
+	// resets workers on signal
+	resetter *resetter.Plugin
+	// workers state for the systemd status and watchdog
+	informer *informer.Plugin
+}
+
+// newContainer creates endure container, registers the config plugin with the selected plugins and initializes them.
+// Plugins are not served yet, so network listeners are not bound.
+func newContainer(
+	cfgFile string, override []string, containerCfg *container.Config, plugins []interface{},
+) (*endure.Endure, *inproc, error) {
+	const op = errors.Op("serve_new_container")
+
+	cfg := &configImpl.Plugin{
+		Path:    cfgFile,
+		Prefix:  rrPrefix,
+		Timeout: containerCfg.GracePeriod,
+		Flags:   override,
+		Version: meta.Version(),
+	}
+
+	endureContainer, err := container.Build(containerCfg, cfg, plugins)
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+
+	pl := &inproc{}
+
+	for i := 0; i < len(plugins); i++ {
+		switch p := plugins[i].(type) {
+		case *resetter.Plugin:
+			pl.resetter = p
+		case *informer.Plugin:
+			pl.informer = p
+		}
+	}
+
+	return endureContainer, pl, nil
+}
+
+// build creates the container for the configuration snapshot. Addresses of the activated sockets (fd://name) are
+// replaced with the bridged unix sockets.
+func (s *server) build(cfgFile string, snap *snapshot) (*endure.Endure, *inproc, error) {
+	const op = errors.Op("serve_build_container")
+
+	ov, err := s.fds.Overrides(snap.settings)
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+
+	override := make([]string, 0, len(s.override)+len(ov))
+	override = append(override, s.override...)
+	override = append(override, ov...)
+
+	// plugins disabled by the --only/--exclude flags and the endure.disabled_plugins key
+	exclude := make([]string, 0, len(s.exclude)+len(snap.containerCfg.DisabledPlugins))
+	exclude = append(exclude, s.exclude...)
+	exclude = append(exclude, snap.containerCfg.DisabledPlugins...)
+
+	plugins, err := container.Select(container.Plugins(), s.only, exclude)
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+
+	// `rr upgrade` RPC, requests are handled by the serve loop
+	plugins = append(plugins, upgrade.NewPlugin(s.upgrades))
+
+	return newContainer(cfgFile, override, snap.containerCfg, plugins)
+}
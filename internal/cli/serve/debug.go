@@ -0,0 +1,31 @@
+package serve
+
+import (
+	"fmt"
+	"net"
+
+	dbg "github.com/roadrunner-server/roadrunner/v2/internal/debug"
+)
+
+// startDebugServer binds the debug server synchronously (so bind errors are not lost) and serves it in background.
+func startDebugServer(cfg *dbg.Config, addr *string, silent bool) (*dbg.Server, error) {
+	if addr != nil && *addr != "" {
+		cfg.Address = *addr
+	}
+
+	cfg.ApplyProfileRates()
+
+	l, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := dbg.NewServer(cfg.Options()...)
+	go func() { _ = srv.Serve(l) }()
+
+	if !silent {
+		fmt.Printf("[INFO] debug server started on: http://%s/debug/pprof/\n", l.Addr().String())
+	}
+
+	return &srv, nil
+}
@@ -0,0 +1,135 @@
+package serve
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/sdnotify"
+
+	"github.com/roadrunner-server/informer/v2"
+)
+
+const (
+	// statusInterval is the systemd STATUS refresh interval when the watchdog is disabled.
+	statusInterval = time.Second * 10
+	// watchdogGrace is how long the watchdog is still pinged while the informer is unreachable or no pool has a ready
+	// worker (the workers are restarted or boot slowly).
+	watchdogGrace = time.Minute
+)
+
+// informerRPC is the RPC service of the informer plugin.
+type informerRPC interface {
+	List(_ bool, list *[]string) error
+}
+
+// notify sends states to systemd, failures are only reported since the service manager might be gone.
+func notify(states ...string) {
+	if _, err := sdnotify.Notify(states...); err != nil {
+		fmt.Printf("sd_notify: %v\n", err)
+	}
+}
+
+// notifyInterval returns the interval of the WATCHDOG and STATUS updates, zero when not started by systemd.
+func notifyInterval() (time.Duration, bool) {
+	if !sdnotify.Enabled() {
+		return 0, false
+	}
+
+	wd, err := sdnotify.WatchdogInterval()
+	if err != nil {
+		fmt.Printf("sd_notify: invalid watchdog interval: %v\n", err)
+	}
+
+	if wd > 0 {
+		return wd, true
+	}
+
+	return statusInterval, false
+}
+
+// workersStatus returns the STATUS line with ready workers per plugin (`http: 4/4 ready`), prefixed with `degraded`
+// when a plugin has no ready workers. The container is alive when the informer is reachable and at least one pool has
+// a ready worker (or there are no pools at all).
+func workersStatus(inf *informer.Plugin) (string, bool) {
+	if inf == nil {
+		return "serving", true
+	}
+
+	r, ok := inf.RPC().(informerRPC)
+	if !ok {
+		return "serving", true
+	}
+
+	var plugins []string
+	if err := r.List(true, &plugins); err != nil {
+		return fmt.Sprintf("serving; workers state unavailable: %v", err), false
+	}
+
+	if len(plugins) == 0 {
+		return "serving", true
+	}
+
+	sort.Strings(plugins)
+
+	healthy, alive := true, false
+	parts := make([]string, 0, len(plugins))
+
+	for _, p := range plugins {
+		workers := inf.Workers(p)
+
+		ready := 0
+		for i := 0; i < len(workers); i++ {
+			if workers[i].Status == "ready" || workers[i].Status == "working" {
+				ready++
+			}
+		}
+
+		if ready == 0 {
+			healthy = false
+		} else {
+			alive = true
+		}
+
+		parts = append(parts, fmt.Sprintf("%s: %d/%d ready", p, ready, len(workers)))
+	}
+
+	if !healthy {
+		return "degraded; " + strings.Join(parts, ", "), alive
+	}
+
+	return strings.Join(parts, ", "), alive
+}
+
+// notifyReady tells systemd that the container is serving, MAINPID follows the process after the binary upgrade
+// (requires NotifyAccess=all in the unit).
+func (s *server) notifyReady() {
+	status, _ := workersStatus(s.informer)
+	// the grace period starts with the (re)started container
+	s.alive = time.Now()
+
+	notify(sdnotify.Ready, sdnotify.MainPID(os.Getpid()), sdnotify.Status(status))
+}
+
+// notifyStatus refreshes the STATUS line and pings the watchdog. It is called by the serve loop, which does not tick
+// while the process hangs or the supervisor restarts the container. The watchdog is pinged while the container is
+// alive (see workersStatus) or was alive within the grace period, so restarting workers do not get the service killed,
+// but a container without ready workers or with unreachable informer does after the grace period.
+func (s *server) notifyStatus(watchdog bool) {
+	status, alive := workersStatus(s.informer)
+
+	now := time.Now()
+	if alive {
+		s.alive = now
+	}
+
+	if watchdog && s.container != nil && now.Sub(s.alive) < watchdogGrace {
+		notify(sdnotify.Status(status), sdnotify.Watchdog)
+
+		return
+	}
+
+	notify(sdnotify.Status(status))
+}
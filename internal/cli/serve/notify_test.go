@@ -0,0 +1,100 @@
+package serve
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/api/v2/state/process"
+	endure "github.com/roadrunner-server/endure/pkg/container"
+	"github.com/roadrunner-server/informer/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type workers struct {
+	name   string
+	states []*process.State
+}
+
+func (w *workers) Name() string              { return w.name }
+func (w *workers) Workers() []*process.State { return w.states }
+
+func TestWorkersStatus(t *testing.T) {
+	status, alive := workersStatus(nil)
+	assert.Equal(t, "serving", status)
+	assert.True(t, alive)
+
+	inf := &informer.Plugin{}
+	require.NoError(t, inf.Init())
+
+	http := &workers{name: "http", states: []*process.State{
+		{Pid: 1, Status: "ready"},
+		{Pid: 2, Status: "working"},
+		{Pid: 3, Status: "invalid"},
+	}}
+	inf.CollectWorkers(http, http)
+
+	status, alive = workersStatus(inf)
+	assert.Equal(t, "http: 2/3 ready", status)
+	assert.True(t, alive)
+
+	grpc := &workers{name: "grpc", states: []*process.State{{Pid: 4, Status: "stopped"}}}
+	inf.CollectWorkers(grpc, grpc)
+
+	// a pool has ready workers
+	status, alive = workersStatus(inf)
+	assert.Equal(t, "degraded; grpc: 0/1 ready, http: 2/3 ready", status)
+	assert.True(t, alive)
+
+	inf = &informer.Plugin{}
+	require.NoError(t, inf.Init())
+	inf.CollectWorkers(grpc, grpc)
+
+	status, alive = workersStatus(inf)
+	assert.Equal(t, "degraded; grpc: 0/1 ready", status)
+	assert.False(t, alive)
+}
+
+func TestNotifyStatus_Degraded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	inf := &informer.Plugin{}
+	require.NoError(t, inf.Init())
+
+	grpc := &workers{name: "grpc", states: []*process.State{{Pid: 4, Status: "stopped"}}}
+	inf.CollectWorkers(grpc, grpc)
+
+	read := func() string {
+		buf := make([]byte, 1024)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+		n, errR := conn.Read(buf)
+		require.NoError(t, errR)
+
+		return string(buf[:n])
+	}
+
+	// no ready workers within the grace period, the watchdog is pinged
+	srv := &server{container: &endure.Endure{}, informer: inf, alive: time.Now()}
+	srv.notifyStatus(true)
+	assert.Equal(t, "STATUS=degraded; grpc: 0/1 ready\nWATCHDOG=1", read())
+
+	// the grace period is over, only the status is sent
+	srv.alive = time.Now().Add(-watchdogGrace)
+	srv.notifyStatus(true)
+	assert.Equal(t, "STATUS=degraded; grpc: 0/1 ready", read())
+
+	// the workers are ready again
+	grpc.states = []*process.State{{Pid: 5, Status: "ready"}}
+	srv.notifyStatus(true)
+	assert.Equal(t, "STATUS=grpc: 1/1 ready\nWATCHDOG=1", read())
+}
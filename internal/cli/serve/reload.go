@@ -0,0 +1,252 @@
+package serve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/container"
+	"github.com/roadrunner-server/roadrunner/v2/internal/sdnotify"
+
+	"github.com/fsnotify/fsnotify"
+	endure "github.com/roadrunner-server/endure/pkg/container"
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	// editors save files in several steps, wait for the last one
+	reloadDebounce = time.Millisecond * 500
+)
+
+// watchConfig notifies about the config file changes. The directory is watched instead of the file, so the atomic
+// replacements (rename over the file) made by editors and k8s ConfigMaps are not lost.
+func watchConfig(path string, stop <-chan struct{}) (<-chan struct{}, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = w.Add(filepath.Dir(path)); err != nil {
+		_ = w.Close()
+
+		return nil, err
+	}
+
+	changed := make(chan struct{}, 1)
+
+	go func() {
+		defer func() { _ = w.Close() }()
+
+		var debounce <-chan time.Time
+
+		for {
+			select {
+			case <-stop:
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+
+				if filepath.Clean(ev.Name) == filepath.Clean(path) && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					debounce = time.After(reloadDebounce)
+				}
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+			case <-debounce:
+				debounce = nil
+
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return changed, nil
+}
+
+// snapshot is the configuration of the running container.
+type snapshot struct {
+	data         []byte
+	settings     map[string]any
+	containerCfg *container.Config
+}
+
+// readSnapshot reads and validates the configuration file: syntax, ${ENV} references and the endure section.
+func readSnapshot(cfgFile string, override []string) (*snapshot, error) {
+	const op = errors.Op("serve_read_config")
+
+	data, err := os.ReadFile(cfgFile)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(cfgFile)
+
+	if err = v.ReadInConfig(); err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	settings := make(map[string]any, len(v.AllKeys()))
+	for _, key := range v.AllKeys() {
+		val := v.Get(key)
+		if s, ok := val.(string); ok {
+			val = os.ExpandEnv(s)
+		}
+
+		settings[key] = val
+	}
+
+	for _, f := range override {
+		if key, val, ok := strings.Cut(f, "="); ok {
+			settings[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+
+	containerCfg, err := container.NewConfig(cfgFile)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return &snapshot{data: data, settings: settings, containerCfg: containerCfg}, nil
+}
+
+// diffKeys returns sorted list of added (+), removed (-) and changed (~) keys. Values are not shown, they may contain
+// secrets.
+func diffKeys(prev, next map[string]any) []string {
+	var diff []string
+
+	for k, v := range next {
+		old, ok := prev[k]
+
+		switch {
+		case !ok:
+			diff = append(diff, "+"+k)
+		case !reflect.DeepEqual(old, v):
+			diff = append(diff, "~"+k)
+		}
+	}
+
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			diff = append(diff, "-"+k)
+		}
+	}
+
+	sort.Slice(diff, func(i, j int) bool { return diff[i][1:] < diff[j][1:] })
+
+	return diff
+}
+
+// swap replaces the running container with a new one built from the changed configuration. The new container is
+// initialized before the running one is stopped, so invalid configuration and Init errors leave the running container
+// untouched. When the new container fails to Serve, the previous configuration is served again. Error is returned
+// only when nothing is served anymore.
+func (s *server) swap() error {
+	next, err := readSnapshot(s.cfgFile, s.override)
+	if err != nil {
+		fmt.Printf("config reload: invalid configuration, keep running the previous one: %v\n", err)
+
+		return nil
+	}
+
+	diff := diffKeys(s.running.settings, next.settings)
+	if len(diff) == 0 {
+		fmt.Println("config reload: no changes")
+
+		return nil
+	}
+
+	fmt.Printf("config reload: changed keys: %s\n", strings.Join(diff, ", "))
+
+	nextContainer, pl, err := s.build(s.cfgFile, next)
+	if err != nil {
+		fmt.Printf("config reload: new configuration rejected, keep running the previous one: %v\n", err)
+
+		return nil
+	}
+
+	if err = s.container.Stop(); err != nil {
+		fmt.Printf("config reload: failed to stop the running container: %v\n", err)
+	}
+
+	errCh, err := nextContainer.Serve()
+	if err == nil {
+		s.set(nextContainer, errCh, pl, next)
+		fmt.Println("config reload: new configuration applied")
+
+		return nil
+	}
+
+	_ = nextContainer.Stop()
+
+	fmt.Printf("config reload: new configuration failed to serve, rolling back: %v\n", err)
+
+	return s.restore()
+}
+
+// reload applies the changed configuration, the same way for SIGHUP and the configuration file changes.
+func (s *server) reload() error {
+	notify(sdnotify.Reloading)
+
+	if err := s.swap(); err != nil {
+		return err
+	}
+
+	s.notifyReady()
+
+	return nil
+}
+
+// restore serves the running configuration again: after the failed reload or after the container failure. It is read
+// from a temporary copy since the file might have changed.
+func (s *server) restore() error {
+	const op = errors.Op("serve_restore")
+
+	f, err := os.CreateTemp("", "rr-rollback-*"+filepath.Ext(s.cfgFile))
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err = f.Write(s.running.data); err != nil {
+		_ = f.Close()
+
+		return errors.E(op, err)
+	}
+
+	_ = f.Close()
+
+	prev, pl, err := s.build(f.Name(), s.running)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	errCh, err := prev.Serve()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	s.set(prev, errCh, pl, s.running)
+
+	return nil
+}
+
+func (s *server) set(c *endure.Endure, errCh <-chan *endure.Result, pl *inproc, snap *snapshot) {
+	s.container = c
+	s.errCh = errCh
+	s.running = snap
+	s.informer = pl.informer
+	s.diag.setResetter(pl.resetter)
+}
@@ -0,0 +1,68 @@
+package serve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffKeys(t *testing.T) {
+	prev := map[string]any{"http.address": ":8080", "rpc.listen": "tcp://:6001", "logs.level": "debug"}
+	next := map[string]any{"http.address": ":8081", "rpc.listen": "tcp://:6001", "server.command": "php worker.php"}
+
+	assert.Equal(t, []string{"~http.address", "-logs.level", "+server.command"}, diffKeys(prev, next))
+	assert.Empty(t, diffKeys(prev, prev))
+}
+
+func TestReadSnapshot(t *testing.T) {
+	t.Setenv("RR_TEST_ADDRESS", ":8080")
+
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	cfg := "http:\n  address: ${RR_TEST_ADDRESS}\nendure:\n  grace_period: 1s\n"
+	require.NoError(t, os.WriteFile(path, []byte(cfg), 0o600))
+
+	snap, err := readSnapshot(path, []string{"rpc.listen=tcp://127.0.0.1:6001"})
+	require.NoError(t, err)
+
+	assert.Equal(t, ":8080", snap.settings["http.address"])
+	assert.Equal(t, "tcp://127.0.0.1:6001", snap.settings["rpc.listen"])
+	assert.Equal(t, time.Second, snap.containerCfg.GracePeriod)
+
+	require.NoError(t, os.WriteFile(path, []byte("http: [address"), 0o600))
+
+	_, err = readSnapshot(path, nil)
+	assert.Error(t, err)
+}
+
+func TestWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: '2.7'\n"), 0o600))
+
+	done := make(chan struct{})
+	defer close(done)
+
+	changed, err := watchConfig(path, done)
+	require.NoError(t, err)
+
+	// unrelated files are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("FOO=BAR\n"), 0o600))
+
+	select {
+	case <-changed:
+		t.Fatal("unexpected change notification")
+	case <-time.After(reloadDebounce * 2):
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte("version: '2.7'\nrpc:\n  listen: tcp://127.0.0.1:6001\n"), 0o600))
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second * 5):
+		t.Fatal("change notification was not received")
+	}
+}
@@ -0,0 +1,121 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	dbg "github.com/roadrunner-server/roadrunner/v2/internal/debug"
+
+	"github.com/roadrunner-server/resetter/v2"
+)
+
+// resetterRPC is the RPC service of the resetter plugin.
+type resetterRPC interface {
+	List(_ bool, list *[]string) error
+	Reset(service string, done *bool) error
+}
+
+// diagnostics handles the operators' signals: goroutine dump, workers reset and execution trace. It works in-process,
+// so it's usable even when RPC is wedged.
+type diagnostics struct {
+	cfg      *dbg.Config
+	version  string
+	mu       sync.Mutex
+	resetter *resetter.Plugin
+	// execution trace in progress
+	tracing int32
+}
+
+// setResetter replaces the resetter plugin after the container reload.
+func (d *diagnostics) setResetter(r *resetter.Plugin) {
+	d.mu.Lock()
+	d.resetter = r
+	d.mu.Unlock()
+}
+
+// dumpGoroutines writes full goroutine dump into the profiles directory.
+func (d *diagnostics) dumpGoroutines() {
+	p := dbg.NewProfiler(&dbg.ProfilingConfig{
+		Dir:        d.cfg.Profiling.Dir,
+		Profiles:   []string{dbg.ProfileGoroutine},
+		FilePrefix: dbg.PrefixSignal,
+	}, d.version)
+
+	files, err := p.CaptureAll(context.Background(), "signal")
+	if err != nil {
+		fmt.Printf("goroutine dump failed: %v\n", err)
+
+		return
+	}
+
+	fmt.Printf("goroutine dump written: %v\n", files)
+}
+
+// resetWorkers resets workers pools of all plugins registered in the resetter plugin.
+func (d *diagnostics) resetWorkers() {
+	d.mu.Lock()
+	rst := d.resetter
+	d.mu.Unlock()
+
+	if rst == nil {
+		fmt.Println("workers reset skipped: resetter plugin is not registered")
+
+		return
+	}
+
+	r, ok := rst.RPC().(resetterRPC)
+	if !ok {
+		fmt.Println("workers reset skipped: unsupported resetter plugin")
+
+		return
+	}
+
+	var plugins []string
+	if err := r.List(true, &plugins); err != nil {
+		fmt.Printf("workers reset failed: %v\n", err)
+
+		return
+	}
+
+	for _, p := range plugins {
+		var done bool
+		if err := r.Reset(p, &done); err != nil {
+			fmt.Printf("plugin [%s] reset failed: %v\n", p, err)
+
+			continue
+		}
+
+		fmt.Printf("plugin reset: [%s]\n", p)
+	}
+}
+
+// recordTrace records runtime/trace for the configured duration, only one trace may be recorded at a time.
+func (d *diagnostics) recordTrace() {
+	if !atomic.CompareAndSwapInt32(&d.tracing, 0, 1) {
+		fmt.Println("execution trace is already in progress")
+
+		return
+	}
+
+	defer atomic.StoreInt32(&d.tracing, 0)
+
+	fmt.Printf("recording execution trace for %s\n", d.cfg.TraceDuration)
+
+	p := dbg.NewProfiler(&dbg.ProfilingConfig{
+		Dir:         d.cfg.Profiling.Dir,
+		Profiles:    []string{dbg.ProfileTrace},
+		CPUDuration: d.cfg.TraceDuration,
+		FilePrefix:  dbg.PrefixSignal,
+	}, d.version)
+
+	files, err := p.CaptureAll(context.Background(), "signal")
+	if err != nil {
+		fmt.Printf("execution trace failed: %v\n", err)
+
+		return
+	}
+
+	fmt.Printf("execution trace written: %v\n", files)
+}
@@ -0,0 +1,31 @@
+package serve
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	dbg "github.com/roadrunner-server/roadrunner/v2/internal/debug"
+
+	"github.com/roadrunner-server/resetter/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostics(t *testing.T) {
+	cfg := &dbg.Config{TraceDuration: time.Second}
+	cfg.Profiling.Dir = t.TempDir()
+
+	r := &resetter.Plugin{}
+	require.NoError(t, r.Init())
+
+	d := &diagnostics{cfg: cfg, version: "2.11.0", resetter: r}
+
+	d.dumpGoroutines()
+	d.resetWorkers()
+	d.recordTrace()
+
+	entries, err := os.ReadDir(cfg.Profiling.Dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package serve
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals returns signals which trigger the configuration reload.
+func reloadSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP}
+}
+
+// diagnosticSignals returns signals handled by the diagnostics.
+func diagnosticSignals() []os.Signal {
+	return []os.Signal{syscall.SIGQUIT, syscall.SIGUSR1, syscall.SIGUSR2}
+}
+
+// handle dispatches the signal, long-running actions do not block the caller.
+func (d *diagnostics) handle(sig os.Signal) {
+	switch sig {
+	case syscall.SIGQUIT:
+		d.dumpGoroutines()
+	case syscall.SIGUSR1:
+		go d.resetWorkers()
+	case syscall.SIGUSR2:
+		go d.recordTrace()
+	}
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package serve
+
+import (
+	"os"
+)
+
+// reloadSignals returns signals which trigger the configuration reload, there is no SIGHUP on Windows.
+func reloadSignals() []os.Signal {
+	return nil
+}
+
+// diagnosticSignals returns signals handled by the diagnostics, there are no such signals on Windows.
+func diagnosticSignals() []os.Signal {
+	return nil
+}
+
+func (d *diagnostics) handle(os.Signal) {}
@@ -0,0 +1,103 @@
+package serve
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/sdnotify"
+
+	endure "github.com/roadrunner-server/endure/pkg/container"
+)
+
+// maxRestartBackoff caps the exponential restart delay.
+const maxRestartBackoff = time.Minute
+
+// supervisor limits container restarts: the delay doubles with every restart within the window, and no more than
+// maxRestarts are allowed per window.
+type supervisor struct {
+	maxRestarts int
+	window      time.Duration
+	backoff     time.Duration
+
+	restarts []time.Time
+	now      func() time.Time
+}
+
+func newSupervisor(maxRestarts int, window, backoff time.Duration) *supervisor {
+	return &supervisor{
+		maxRestarts: maxRestarts,
+		window:      window,
+		backoff:     backoff,
+		now:         time.Now,
+	}
+}
+
+// next records the restart and returns the delay before it, false when the limit is reached.
+func (s *supervisor) next() (time.Duration, bool) {
+	now := s.now()
+
+	// forget restarts outside the window
+	recent := s.restarts[:0]
+	for _, t := range s.restarts {
+		if now.Sub(t) < s.window {
+			recent = append(recent, t)
+		}
+	}
+
+	s.restarts = recent
+
+	if len(s.restarts) >= s.maxRestarts {
+		return 0, false
+	}
+
+	delay := s.backoff
+	for i := 0; i < len(s.restarts) && delay < maxRestartBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRestartBackoff {
+		delay = maxRestartBackoff
+	}
+
+	s.restarts = append(s.restarts, now)
+
+	return delay, true
+}
+
+// supervise stops the failed container and serves the running configuration again after the backoff delay, failed
+// restarts are retried. Error is returned when the restarts limit is reached, stopped is true when the stop signal
+// interrupted the delay.
+func (s *server) supervise(sup *supervisor, res *endure.Result, stop <-chan struct{}) (bool, error) {
+	if err := s.container.Stop(); err != nil {
+		fmt.Printf("supervisor: failed to stop the container: %v\n", err)
+	}
+
+	for {
+		delay, ok := sup.next()
+		if !ok {
+			return false, fmt.Errorf("error: %w\nplugin: %s\nsupervisor: %d restarts in %s limit reached",
+				res.Error, res.VertexID, sup.maxRestarts, sup.window)
+		}
+
+		fmt.Printf("supervisor: plugin %s failed: %v; restart in %s\n", res.VertexID, res.Error, delay)
+		notify(sdnotify.Status(fmt.Sprintf("restarting, plugin %s failed", res.VertexID)))
+
+		select {
+		case <-stop:
+			return true, nil
+		case <-time.After(delay):
+		}
+
+		if err := s.restore(); err != nil {
+			// the container failed to start, the reason is unknown at this point
+			res = &endure.Result{Error: err, VertexID: "container"}
+
+			continue
+		}
+
+		fmt.Println("supervisor: container restarted")
+		s.notifyReady()
+
+		return false, nil
+	}
+}
@@ -0,0 +1,31 @@
+package serve
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupervisor(t *testing.T) {
+	now := time.Now()
+	sup := newSupervisor(3, time.Minute, time.Second*20)
+	sup.now = func() time.Time { return now }
+
+	for _, want := range []time.Duration{time.Second * 20, time.Second * 40, time.Minute} {
+		delay, ok := sup.next()
+		assert.True(t, ok)
+		assert.Equal(t, want, delay)
+	}
+
+	// limit reached within the window
+	_, ok := sup.next()
+	assert.False(t, ok)
+
+	// the window has passed, backoff starts over
+	now = now.Add(time.Minute)
+
+	delay, ok := sup.next()
+	assert.True(t, ok)
+	assert.Equal(t, time.Second*20, delay)
+}
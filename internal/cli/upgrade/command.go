@@ -0,0 +1,51 @@
+package upgrade
+
+import (
+	"log"
+
+	internalRpc "github.com/roadrunner-server/roadrunner/v2/internal/rpc"
+
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// sync with the internal/upgrade plugin
+	upgradeMethod string = "upgrade.Upgrade"
+)
+
+// NewCommand creates `upgrade` command.
+func NewCommand(cfgFile *string, override *[]string, silent *bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade",
+		Short: "Restart RoadRunner server with the new binary without dropping connections",
+		Long: "Requests the running server (via RPC) to start the rr binary from the same path with the same arguments. " +
+			"TCP listening sockets are passed to the new process, the old one stops gracefully once the new one is " +
+			"serving. Unix sockets are bound again by the new process. Not supported on Windows.",
+		RunE: func(*cobra.Command, []string) error {
+			const op = errors.Op("rr_upgrade")
+
+			if cfgFile == nil {
+				return errors.E(op, errors.Str("no configuration file provided"))
+			}
+
+			client, err := internalRpc.NewClient(*cfgFile, *override)
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = client.Close() }()
+
+			var accepted bool
+			if err = client.Call(upgradeMethod, true, &accepted); err != nil {
+				return errors.E(op, err)
+			}
+
+			if !*silent {
+				log.Println("upgrade requested, the new process takes over once it is serving")
+			}
+
+			return nil
+		},
+	}
+}
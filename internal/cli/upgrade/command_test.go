@@ -0,0 +1,71 @@
+package upgrade_test
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/cli/upgrade"
+	upgradePlugin "github.com/roadrunner-server/roadrunner/v2/internal/upgrade"
+
+	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve starts the upgrade plugin RPC and returns the configuration file pointing to it.
+func serve(t *testing.T, requests chan<- struct{}) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = l.Close() })
+
+	p := upgradePlugin.NewPlugin(requests)
+
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName(p.Name(), p.RPC()))
+
+	go func() {
+		for {
+			conn, errA := l.Accept()
+			if errA != nil {
+				return
+			}
+
+			go srv.ServeCodec(goridgeRpc.NewCodec(conn))
+		}
+	}()
+
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: '2.7'\nrpc:\n  listen: tcp://"+l.Addr().String()+"\n"), 0o600))
+
+	return path
+}
+
+func TestCommandProperties(t *testing.T) {
+	path := ""
+	f := false
+	cmd := upgrade.NewCommand(&path, nil, &f)
+
+	assert.Equal(t, "upgrade", cmd.Use)
+	assert.NotNil(t, cmd.RunE)
+}
+
+func TestExecution(t *testing.T) {
+	requests := make(chan struct{}, 1)
+	path := serve(t, requests)
+	silent := true
+
+	cmd := upgrade.NewCommand(&path, &[]string{}, &silent)
+	require.NoError(t, cmd.Execute())
+	assert.Len(t, requests, 1)
+
+	// the previous request is not handled yet
+	cmd = upgrade.NewCommand(&path, &[]string{}, &silent)
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upgrade is already in progress")
+}
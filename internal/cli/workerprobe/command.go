@@ -0,0 +1,98 @@
+package workerprobe
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/workerenv"
+
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand creates `worker-probe` command.
+func NewCommand(cfgFile *string, override *[]string) *cobra.Command {
+	req := &request{}
+
+	var (
+		header  []string
+		body    string
+		timeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "worker-probe",
+		Short: "Spawn a single worker from the server configuration, send it a test payload and print the exchange",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			const op = errors.Op("rr_worker_probe")
+
+			if cfgFile == nil {
+				return errors.E(op, errors.Str("no configuration file provided"))
+			}
+
+			cfg, err := readConfig(*cfgFile, *override)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			req.header = http.Header{}
+			for _, h := range header {
+				k, v, ok := strings.Cut(h, ":")
+				if !ok {
+					return errors.E(op, fmt.Errorf("invalid header `%s` (Name: value)", h))
+				}
+
+				req.header.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+			}
+
+			if req.body, err = readBody(body); err != nil {
+				return errors.E(op, err)
+			}
+
+			p := &probe{cfg: cfg, timeout: timeout, out: cmd.OutOrStdout()}
+
+			if err = p.run(req); err != nil {
+				return errors.E(op, err)
+			}
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&req.kind, "payload", payloadHTTP, "payload: http (PSR-7 request), jobs (job), raw (--context, --body)")
+	f.StringVar(&req.method, "method", http.MethodGet, "HTTP method")
+	f.StringVar(&req.uri, "uri", "http://localhost/", "HTTP request URI")
+	f.StringVar(&req.job, "job", "probe", "job name of the jobs payload")
+	f.StringArrayVarP(&header, "header", "H", nil, "request or job header (Name: value)")
+	f.StringVar(&req.context, "context", "{}", "context of the raw payload")
+	f.StringVar(&body, "body", "", "payload body, @file reads the file")
+	f.DurationVar(&timeout, "timeout", time.Second*30, "handshake and response timeout")
+
+	return cmd
+}
+
+// readConfig reads the server section, the worker command is required.
+func readConfig(cfgFile string, override []string) (*workerenv.Config, error) {
+	cfg, err := workerenv.Read(cfgFile, override)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Command == "" {
+		return nil, errors.Str("server.command should not be empty")
+	}
+
+	return cfg, nil
+}
+
+func readBody(body string) ([]byte, error) {
+	if strings.HasPrefix(body, "@") {
+		return os.ReadFile(body[1:])
+	}
+
+	return []byte(body), nil
+}
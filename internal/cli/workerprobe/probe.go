@@ -0,0 +1,442 @@
+package workerprobe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/workerenv"
+
+	"github.com/roadrunner-server/goridge/v3/pkg/frame"
+	"github.com/roadrunner-server/goridge/v3/pkg/pipe"
+	"github.com/roadrunner-server/goridge/v3/pkg/relay"
+	"github.com/roadrunner-server/goridge/v3/pkg/socket"
+)
+
+const (
+	payloadHTTP string = "http"
+	payloadJobs string = "jobs"
+	payloadRaw  string = "raw"
+)
+
+// request is the crafted payload sent to the worker.
+type request struct {
+	kind    string
+	method  string
+	uri     string
+	job     string
+	header  http.Header
+	context string
+	body    []byte
+}
+
+// probe spawns a single worker, sends it the request and reports the exchange.
+type probe struct {
+	cfg     *workerenv.Config
+	timeout time.Duration
+	out     io.Writer
+
+	cmd    *exec.Cmd
+	rl     relay.Relay
+	stderr syncBuffer
+	exited chan struct{}
+	ln     net.Listener
+}
+
+// run spawns the worker, makes the pid handshake, sends the request and stops the worker.
+func (p *probe) run(req *request) error {
+	ctx, body, err := req.payload()
+	if err != nil {
+		return err
+	}
+
+	defer p.close()
+
+	start := time.Now()
+
+	if err = p.spawn(req.kind); err != nil {
+		return err
+	}
+
+	pid, err := p.handshake()
+	if err != nil {
+		return fmt.Errorf("worker handshake failed: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(p.out, "handshake: pid %d, %s\n", pid, time.Since(start).Round(time.Microsecond))
+
+	start = time.Now()
+
+	if err = p.send(frame.CodecJSON, ctx, body); err != nil {
+		return err
+	}
+
+	fr, err := p.receive()
+	if err != nil {
+		return fmt.Errorf("no response from the worker: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(p.out, "exec: %s\n", time.Since(start).Round(time.Microsecond))
+
+	return p.report(fr)
+}
+
+// spawn starts the worker command and connects the relay.
+func (p *probe) spawn(kind string) error {
+	args := strings.Split(p.cfg.Command, " ")
+
+	p.cmd = exec.Command(args[0], args[1:]...) //nolint:gosec
+	p.cmd.Stderr = &p.stderr
+	p.cmd.Env = append(p.cfg.Environ(), "RR_RELAY="+p.cfg.Relay)
+
+	if kind != payloadRaw {
+		p.cmd.Env = append(p.cmd.Env, "RR_MODE="+kind)
+	}
+
+	if err := workerenv.Credential(p.cmd, p.cfg.User, p.cfg.Group); err != nil {
+		return err
+	}
+
+	if p.cfg.Relay == "pipes" {
+		in, err := p.cmd.StdinPipe()
+		if err != nil {
+			return err
+		}
+
+		out, err := p.cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+
+		p.rl = &tracer{Relay: pipe.NewPipeRelay(out, in), out: p.out}
+
+		return p.start()
+	}
+
+	network, address, ok := strings.Cut(p.cfg.Relay, "://")
+	if !ok || (network != "tcp" && network != "unix") {
+		return fmt.Errorf("invalid relay `%s` (pipes, tcp://127.0.0.1:7000, unix:///tmp/rr.sock)", p.cfg.Relay)
+	}
+
+	if network == "unix" {
+		_ = os.Remove(address)
+	}
+
+	var err error
+	if p.ln, err = net.Listen(network, address); err != nil {
+		return err
+	}
+
+	if err = p.start(); err != nil {
+		return err
+	}
+
+	conns := make(chan net.Conn, 1)
+
+	go func() {
+		if conn, errA := p.ln.Accept(); errA == nil {
+			conns <- conn
+		}
+	}()
+
+	select {
+	case conn := <-conns:
+		p.rl = &tracer{Relay: socket.NewSocketRelay(conn), out: p.out}
+
+		return nil
+	case <-p.exited:
+		return fmt.Errorf("worker exited before connecting to %s: %s", p.cfg.Relay, p.cmd.ProcessState)
+	case <-time.After(p.timeout):
+		return fmt.Errorf("worker did not connect to %s in %s", p.cfg.Relay, p.timeout)
+	}
+}
+
+func (p *probe) start() error {
+	if err := p.cmd.Start(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(p.out, "spawned: %s (pid %d, relay %s)\n", p.cfg.Command, p.cmd.Process.Pid, p.cfg.Relay)
+
+	p.exited = make(chan struct{})
+
+	go func() {
+		_ = p.cmd.Wait()
+		close(p.exited)
+	}()
+
+	return nil
+}
+
+func (p *probe) handshake() (int, error) {
+	if err := p.control(map[string]int{"pid": os.Getpid()}); err != nil {
+		return 0, err
+	}
+
+	fr, err := p.receive()
+	if err != nil {
+		return 0, err
+	}
+
+	if fr.ReadFlags()&frame.CONTROL == 0 {
+		return 0, fmt.Errorf("unexpected response, no CONTROL flag: %q", fr.Payload())
+	}
+
+	var rsp struct {
+		Pid int `json:"pid"`
+	}
+
+	if err = json.Unmarshal(fr.Payload(), &rsp); err != nil {
+		return 0, fmt.Errorf("invalid pid response %q: %w", fr.Payload(), err)
+	}
+
+	if rsp.Pid <= 0 {
+		return 0, fmt.Errorf("pid should be greater than 0, got %d", rsp.Pid)
+	}
+
+	return rsp.Pid, nil
+}
+
+func (p *probe) control(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	fr := frame.NewFrame()
+	fr.WriteVersion(fr.Header(), frame.Version1)
+	fr.WriteFlags(fr.Header(), frame.CONTROL, frame.CodecJSON)
+	fr.WritePayloadLen(fr.Header(), uint32(len(data)))
+	fr.WritePayload(data)
+	fr.WriteCRC(fr.Header())
+
+	return p.rl.Send(fr)
+}
+
+func (p *probe) send(flags byte, ctx, body []byte) error {
+	data := make([]byte, 0, len(ctx)+len(body))
+	data = append(data, ctx...)
+	data = append(data, body...)
+
+	fr := frame.NewFrame()
+	fr.WriteVersion(fr.Header(), frame.Version1)
+	fr.WriteFlags(fr.Header(), flags)
+	fr.WriteOptions(fr.HeaderPtr(), uint32(len(ctx)))
+	fr.WritePayloadLen(fr.Header(), uint32(len(data)))
+	fr.WritePayload(data)
+	fr.WriteCRC(fr.Header())
+
+	return p.rl.Send(fr)
+}
+
+// receive waits for the frame, fails when the worker exits or the timeout passes.
+func (p *probe) receive() (*frame.Frame, error) {
+	fr := frame.NewFrame()
+	errCh := make(chan error, 1)
+
+	go func() { errCh <- p.rl.Receive(fr) }()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return nil, err
+		}
+
+		return fr, nil
+	case <-p.exited:
+		// the frame might be sent right before the exit
+		select {
+		case err := <-errCh:
+			if err == nil {
+				return fr, nil
+			}
+		case <-time.After(time.Millisecond * 100):
+		}
+
+		return nil, fmt.Errorf("worker exited: %s", p.cmd.ProcessState)
+	case <-time.After(p.timeout):
+		return nil, fmt.Errorf("timed out after %s", p.timeout)
+	}
+}
+
+// report prints the worker response.
+func (p *probe) report(fr *frame.Frame) error {
+	data := fr.Payload()
+
+	if fr.ReadFlags()&frame.ERROR != 0 {
+		_, _ = fmt.Fprintf(p.out, "error: %s\n", data)
+
+		return fmt.Errorf("worker responded with the error: %s", data)
+	}
+
+	var offset uint32
+	if opts := fr.ReadOptions(fr.Header()); len(opts) > 0 {
+		offset = opts[0]
+	}
+
+	if int(offset) > len(data) {
+		return fmt.Errorf("bad response, context length %d is out of range", offset)
+	}
+
+	_, _ = fmt.Fprintf(p.out, "context: %s\nbody:\n%s\n", data[:offset], data[offset:])
+
+	return nil
+}
+
+// close stops the worker (stop command, then kill) and prints its stderr.
+func (p *probe) close() {
+	if p.cmd != nil && p.exited != nil {
+		if p.rl != nil {
+			_ = p.control(map[string]bool{"stop": true})
+		}
+
+		select {
+		case <-p.exited:
+		case <-time.After(time.Second * 5):
+			_ = p.cmd.Process.Kill()
+			<-p.exited
+		}
+	}
+
+	if p.ln != nil {
+		_ = p.ln.Close()
+	}
+
+	if p.stderr.Len() > 0 {
+		_, _ = fmt.Fprintf(p.out, "stderr:\n%s", p.stderr.String())
+	}
+}
+
+// payload encodes the request context and body the way the plugin of the request kind does.
+func (r *request) payload() ([]byte, []byte, error) {
+	var ctx interface{}
+
+	switch r.kind {
+	case payloadHTTP:
+		u, err := url.Parse(r.uri)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ctx = map[string]interface{}{
+			"remoteAddr": "127.0.0.1",
+			"protocol":   "HTTP/1.1",
+			"method":     r.method,
+			"uri":        u.String(),
+			"headers":    r.header,
+			"cookies":    map[string]string{},
+			"rawQuery":   u.RawQuery,
+			"parsed":     false,
+			"attributes": map[string]interface{}{},
+		}
+	case payloadJobs:
+		ctx = map[string]interface{}{
+			"id":       "probe",
+			"job":      r.job,
+			"headers":  r.header,
+			"pipeline": "probe",
+		}
+	case payloadRaw:
+		return []byte(r.context), r.body, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown payload `%s` (allowed: %s, %s, %s)",
+			r.kind, payloadHTTP, payloadJobs, payloadRaw)
+	}
+
+	data, err := json.Marshal(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, r.body, nil
+}
+
+// tracer prints every frame sent to or received from the worker.
+type tracer struct {
+	relay.Relay
+	out io.Writer
+}
+
+func (t *tracer) Send(fr *frame.Frame) error {
+	t.trace(">", fr)
+
+	return t.Relay.Send(fr)
+}
+
+func (t *tracer) Receive(fr *frame.Frame) error {
+	if err := t.Relay.Receive(fr); err != nil {
+		return err
+	}
+
+	t.trace("<", fr)
+
+	return nil
+}
+
+func (t *tracer) trace(dir string, fr *frame.Frame) {
+	_, _ = fmt.Fprintf(t.out, "%s frame: flags=%s options=%v length=%d\n%s   %q\n",
+		dir, flagNames(fr.ReadFlags()), fr.ReadOptions(fr.Header()), len(fr.Payload()), dir, fr.Payload())
+}
+
+func flagNames(flags byte) string {
+	names := make([]string, 0, 4)
+
+	for _, f := range []struct {
+		flag byte
+		name string
+	}{
+		{frame.CONTROL, "CONTROL"},
+		{frame.CodecRaw, "RAW"},
+		{frame.CodecJSON, "JSON"},
+		{frame.CodecMsgpack, "MSGPACK"},
+		{frame.CodecGob, "GOB"},
+		{frame.CodecProto, "PROTO"},
+		{frame.ERROR, "ERROR"},
+	} {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "0"
+	}
+
+	return strings.Join(names, "|")
+}
+
+// syncBuffer collects the worker stderr written from the exec goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Len()
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
@@ -0,0 +1,112 @@
+package workerprobe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/workerenv"
+	"github.com/roadrunner-server/roadrunner/v2/pkg/worker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envTestWorker = "RR_PROBE_TEST_WORKER"
+
+func TestMain(m *testing.M) {
+	// launched by the probe under the test
+	switch os.Getenv(envTestWorker) {
+	case "":
+		os.Exit(m.Run())
+	case "crash":
+		_, _ = fmt.Fprintln(os.Stderr, "fatal error")
+		os.Exit(3)
+	}
+
+	rl, err := worker.NewRelay()
+	if err == nil {
+		err = worker.Serve(rl, func(p *worker.Payload) (*worker.Payload, error) {
+			if os.Getenv(envTestWorker) == "error" {
+				return nil, errors.New("worker failed")
+			}
+
+			return worker.HTTPResponse(http.StatusOK, http.Header{}, append([]byte(os.Getenv("RR_MODE")+":"), p.Body...))
+		})
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func testProbe(mode, relay string) (*probe, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+
+	return &probe{
+		cfg: &workerenv.Config{
+			Command: os.Args[0] + " -test.run=^$",
+			Env:     map[string]string{envTestWorker: mode},
+			Relay:   relay,
+		},
+		timeout: time.Second * 10,
+		out:     out,
+	}, out
+}
+
+func TestProbe_Run(t *testing.T) {
+	for _, relay := range []string{"pipes", "tcp://127.0.0.1:17321", "unix://" + filepath.Join(t.TempDir(), "w.sock")} {
+		p, out := testProbe("echo", relay)
+
+		req := &request{kind: payloadHTTP, method: http.MethodPost, uri: "http://localhost/", body: []byte("hi")}
+
+		require.NoError(t, p.run(req))
+		assert.Contains(t, out.String(), "handshake: pid ", relay)
+		assert.Contains(t, out.String(), "< frame: flags=JSON", relay)
+		assert.Contains(t, out.String(), "body:\nhttp:hi\n", relay)
+	}
+}
+
+func TestProbe_Failures(t *testing.T) {
+	p, out := testProbe("crash", "pipes")
+
+	err := p.run(&request{kind: payloadRaw})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handshake failed")
+	assert.Contains(t, out.String(), "stderr:\nfatal error\n")
+
+	p, out = testProbe("error", "pipes")
+
+	err = p.run(&request{kind: payloadJobs, job: "ping"})
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "error: worker failed")
+
+	p, _ = testProbe("echo", "pipes")
+	assert.Error(t, p.run(&request{kind: "grpc"}))
+}
+
+func TestRequest_Payload(t *testing.T) {
+	req := &request{kind: payloadHTTP, method: http.MethodPut, uri: "http://localhost/foo?a=b", header: http.Header{}}
+
+	ctx, _, err := req.payload()
+	require.NoError(t, err)
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(ctx, &m))
+	assert.Equal(t, "PUT", m["method"])
+	assert.Equal(t, "a=b", m["rawQuery"])
+
+	req = &request{kind: payloadRaw, context: `{"x":1}`, body: []byte("body")}
+
+	ctx, body, err := req.payload()
+	require.NoError(t, err)
+	assert.Equal(t, `{"x":1}`, string(ctx))
+	assert.Equal(t, "body", string(body))
+}
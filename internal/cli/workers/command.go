@@ -18,42 +18,65 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	informerList    string = "informer.List"
+	informerWorkers string = "informer.Workers"
+	informerJobs    string = "informer.Jobs"
+	// this is only one exception to Render the workers, service plugin has the same workers as other plugins,
+	// but they are RAW processes and needs to be handled in a different way. We don't need a special RPC call, but
+	// need a special render method.
+	servicePluginName string = "service"
+)
+
 // NewCommand creates `workers` command.
-func NewCommand(cfgFile *string, override *[]string) *cobra.Command { //nolint:funlen
+func NewCommand(cfgFile *string, override *[]string) *cobra.Command { //nolint:funlen,gocognit
 	var (
 		// interactive workers updates
 		interactive bool
+		// rpc addresses of the fleet instances
+		rpcTargets []string
+		// path to the fleet inventory file
+		inventory string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "workers",
 		Short: "Show information about active RoadRunner workers",
 		RunE: func(_ *cobra.Command, args []string) error {
-			const (
-				op           = errors.Op("handle_workers_command")
-				informerList = "informer.List"
-			)
-
-			if cfgFile == nil {
-				return errors.E(op, errors.Str("no configuration file provided"))
-			}
+			const op = errors.Op("handle_workers_command")
 
-			client, err := internalRpc.NewClient(*cfgFile, *override)
+			instances, err := internalRpc.Targets(rpcTargets, inventory)
 			if err != nil {
-				return err
+				return errors.E(op, err)
 			}
 
-			defer func() { _ = client.Close() }()
+			show := func() error { return showFleet(args, instances) }
+
+			// single instance from the configuration file
+			if len(instances) == 0 {
+				if cfgFile == nil {
+					return errors.E(op, errors.Str("no configuration file provided"))
+				}
+
+				client, errC := internalRpc.NewClient(*cfgFile, *override)
+				if errC != nil {
+					return errC
+				}
+
+				defer func() { _ = client.Close() }()
 
-			plugins := args        // by default we expect plugins list from user
-			if len(plugins) == 0 { // but if nothing was passed - request all informers list
-				if err = client.Call(informerList, true, &plugins); err != nil {
-					return err
+				plugins := args        // by default we expect plugins list from user
+				if len(plugins) == 0 { // but if nothing was passed - request all informers list
+					if err = client.Call(informerList, true, &plugins); err != nil {
+						return err
+					}
 				}
+
+				show = func() error { return showWorkers(plugins, client) }
 			}
 
 			if !interactive {
-				return showWorkers(plugins, client)
+				return show()
 			}
 
 			oss := make(chan os.Signal, 1)
@@ -73,7 +96,7 @@ func NewCommand(cfgFile *string, override *[]string) *cobra.Command { //nolint:f
 					tm.MoveCursor(1, 1)
 					tm.Flush()
 
-					if err = showWorkers(plugins, client); err != nil {
+					if err = show(); err != nil {
 						return errors.E(op, err)
 					}
 				}
@@ -89,19 +112,27 @@ func NewCommand(cfgFile *string, override *[]string) *cobra.Command { //nolint:f
 		"render interactive workers table",
 	)
 
+	cmd.Flags().StringArrayVarP(
+		&rpcTargets,
+		"rpc",
+		"",
+		nil,
+		"RPC address of the instance (name=tcp://host:6001), may be repeated to query many instances at once",
+	)
+
+	cmd.Flags().StringVarP(
+		&inventory,
+		"inventory",
+		"",
+		"",
+		"path to the inventory file with the named instances to query",
+	)
+
 	return cmd
 }
 
 func showWorkers(plugins []string, client *rpc.Client) error {
-	const (
-		op              = errors.Op("show_workers")
-		informerWorkers = "informer.Workers"
-		informerJobs    = "informer.Jobs"
-		// this is only one exception to Render the workers, service plugin has the same workers as other plugins,
-		// but they are RAW processes and needs to be handled in a different way. We don't need a special RPC call, but
-		// need a special render method.
-		servicePluginName = "service"
-	)
+	const op = errors.Op("show_workers")
 
 	for _, plugin := range plugins {
 		list := &informer.WorkerList{}
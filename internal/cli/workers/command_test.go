@@ -24,6 +24,8 @@ func TestCommandFlags(t *testing.T) {
 		wantDefault   string
 	}{
 		{giveName: "interactive", wantShorthand: "i", wantDefault: "false"},
+		{giveName: "rpc", wantShorthand: "", wantDefault: "[]"},
+		{giveName: "inventory", wantShorthand: "", wantDefault: ""},
 	}
 
 	for _, tt := range cases {
@@ -0,0 +1,148 @@
+package workers
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/roadrunner-server/api/v2/plugins/jobs"
+	"github.com/roadrunner-server/errors"
+	"github.com/roadrunner-server/informer/v2"
+	internalRpc "github.com/roadrunner-server/roadrunner/v2/internal/rpc"
+)
+
+// instanceState is a snapshot of the workers and jobs received from one instance.
+type instanceState struct {
+	plugins []string
+	workers map[string]*informer.WorkerList
+	jobs    map[string][]*jobs.State
+}
+
+// showFleet requests workers of every instance concurrently and renders aggregated tables with an instance column.
+// Unreachable instances are reported, but do not prevent rendering of the others.
+func showFleet(plugins []string, instances []internalRpc.Instance) error {
+	const op = errors.Op("show_fleet_workers")
+
+	var mu sync.Mutex
+	states := make(map[string]*instanceState, len(instances))
+
+	fetch := func(inst internalRpc.Instance, client internalRpc.Caller) error {
+		st, err := collect(plugins, client)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		states[inst.Name] = st
+		mu.Unlock()
+
+		return nil
+	}
+
+	errs := internalRpc.Each(instances, internalRpc.CallTimeout, fetch)
+
+	// keep plugins order stable: the order in which they were first seen walking over the instances
+	var order []string
+	seen := make(map[string]struct{})
+
+	for _, inst := range instances {
+		st, ok := states[inst.Name]
+		if !ok {
+			continue
+		}
+
+		for _, p := range st.plugins {
+			if _, ok := seen[p]; !ok {
+				seen[p] = struct{}{}
+				order = append(order, p)
+			}
+		}
+	}
+
+	for _, plugin := range order {
+		list := make([]InstanceWorkers, 0, len(instances))
+
+		for _, inst := range instances {
+			if st, ok := states[inst.Name]; ok && st.workers[plugin] != nil && len(st.workers[plugin].Workers) > 0 {
+				list = append(list, InstanceWorkers{Instance: inst.Name, Workers: st.workers[plugin].Workers})
+			}
+		}
+
+		if len(list) == 0 {
+			continue
+		}
+
+		fmt.Printf("Workers of [%s]:\n", color.HiYellowString(plugin))
+
+		if plugin == servicePluginName {
+			FleetServiceWorkerTable(os.Stdout, list).Render()
+
+			continue
+		}
+
+		FleetWorkerTable(os.Stdout, list).Render()
+	}
+
+	for _, plugin := range order {
+		list := make([]InstanceJobs, 0, len(instances))
+
+		for _, inst := range instances {
+			if st, ok := states[inst.Name]; ok && len(st.jobs[plugin]) > 0 {
+				list = append(list, InstanceJobs{Instance: inst.Name, Jobs: st.jobs[plugin]})
+			}
+		}
+
+		if len(list) == 0 {
+			continue
+		}
+
+		fmt.Printf("Jobs of [%s]:\n", color.HiYellowString(plugin))
+		FleetJobsTable(os.Stdout, list).Render()
+	}
+
+	err := internalRpc.Summarize(instances, errs, func(inst internalRpc.Instance, err error) {
+		_, _ = color.New(color.FgHiRed).Fprintf(os.Stderr, "instance [%s] (%s) failed: %v\n", inst.Name, inst.Address, err)
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// collect requests workers and jobs of the plugins from the single instance. When plugins list is empty, the
+// instance's own informers list is used.
+func collect(plugins []string, client internalRpc.Caller) (*instanceState, error) {
+	st := &instanceState{
+		plugins: plugins,
+		workers: make(map[string]*informer.WorkerList),
+		jobs:    make(map[string][]*jobs.State),
+	}
+
+	if len(st.plugins) == 0 {
+		if err := client.Call(informerList, true, &st.plugins); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, plugin := range st.plugins {
+		list := &informer.WorkerList{}
+
+		if err := client.Call(informerWorkers, plugin, &list); err != nil {
+			return nil, err
+		}
+
+		st.workers[plugin] = list
+
+		var jst []*jobs.State
+
+		if err := client.Call(informerJobs, plugin, &jst); err != nil {
+			return nil, err
+		}
+
+		st.jobs[plugin] = jst
+	}
+
+	return st, nil
+}
@@ -94,6 +94,99 @@ func JobsTable(writer io.Writer, jobs []*jobs.State) *tablewriter.Table {
 	return tw
 }
 
+// InstanceWorkers binds the workers list to the instance it was received from.
+type InstanceWorkers struct {
+	Instance string
+	Workers  []*process.State
+}
+
+// InstanceJobs binds the pipelines list to the instance it was received from.
+type InstanceJobs struct {
+	Instance string
+	Jobs     []*jobs.State
+}
+
+// FleetWorkerTable renders table with information about workers of many rr servers.
+func FleetWorkerTable(writer io.Writer, list []InstanceWorkers) *tablewriter.Table {
+	tw := tablewriter.NewWriter(writer)
+	tw.SetHeader([]string{"Instance", "PID", "Status", "Execs", "Memory", "CPU%", "Created"})
+	tw.SetColMinWidth(0, 10)
+	tw.SetColMinWidth(1, 7)
+	tw.SetColMinWidth(2, 9)
+	tw.SetColMinWidth(3, 7)
+	tw.SetColMinWidth(4, 7)
+	tw.SetColMinWidth(5, 7)
+	tw.SetColMinWidth(6, 18)
+
+	for _, iw := range list {
+		for i := 0; i < len(iw.Workers); i++ {
+			tw.Append([]string{
+				iw.Instance,
+				strconv.Itoa(iw.Workers[i].Pid),
+				renderStatus(iw.Workers[i].Status),
+				renderJobs(iw.Workers[i].NumJobs),
+				humanize.Bytes(iw.Workers[i].MemoryUsage),
+				renderCPU(iw.Workers[i].CPUPercent),
+				renderAlive(time.Unix(0, iw.Workers[i].Created)),
+			})
+		}
+	}
+
+	return tw
+}
+
+// FleetServiceWorkerTable renders table with information about service workers of many rr servers.
+func FleetServiceWorkerTable(writer io.Writer, list []InstanceWorkers) *tablewriter.Table {
+	tw := tablewriter.NewWriter(writer)
+	tw.SetAutoWrapText(false)
+	tw.SetHeader([]string{"Instance", "PID", "Memory", "CPU%", "Command"})
+	tw.SetColMinWidth(0, 10)
+	tw.SetColMinWidth(1, 7)
+	tw.SetColMinWidth(2, 7)
+	tw.SetColMinWidth(3, 7)
+	tw.SetColMinWidth(4, 18)
+	tw.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, iw := range list {
+		for i := 0; i < len(iw.Workers); i++ {
+			tw.Append([]string{
+				iw.Instance,
+				strconv.Itoa(iw.Workers[i].Pid),
+				humanize.Bytes(iw.Workers[i].MemoryUsage),
+				renderCPU(iw.Workers[i].CPUPercent),
+				iw.Workers[i].Command,
+			})
+		}
+	}
+
+	return tw
+}
+
+// FleetJobsTable renders table with information about jobs of many rr servers.
+func FleetJobsTable(writer io.Writer, list []InstanceJobs) *tablewriter.Table {
+	tw := tablewriter.NewWriter(writer)
+	tw.SetAutoWrapText(false)
+	tw.SetHeader([]string{"Instance", "Status", "Pipeline", "Driver", "Queue", "Active", "Delayed", "Reserved"})
+	tw.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, ij := range list {
+		for i := 0; i < len(ij.Jobs); i++ {
+			tw.Append([]string{
+				ij.Instance,
+				renderReady(ij.Jobs[i].Ready),
+				ij.Jobs[i].Pipeline,
+				ij.Jobs[i].Driver,
+				ij.Jobs[i].Queue,
+				strconv.Itoa(int(ij.Jobs[i].Active)),
+				strconv.Itoa(int(ij.Jobs[i].Delayed)),
+				strconv.Itoa(int(ij.Jobs[i].Reserved)),
+			})
+		}
+	}
+
+	return tw
+}
+
 func renderReady(ready bool) string {
 	if ready {
 		return Ready
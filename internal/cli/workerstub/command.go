@@ -0,0 +1,64 @@
+package workerstub
+
+import (
+	"fmt"
+
+	"github.com/roadrunner-server/roadrunner/v2/pkg/worker"
+
+	"github.com/dustin/go-humanize"
+	"github.com/roadrunner-server/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand creates hidden `worker-stub` command.
+func NewCommand() *cobra.Command {
+	o := &options{}
+
+	var grow string
+
+	cmd := &cobra.Command{
+		Use:    "worker-stub",
+		Short:  "Worker speaking the goridge protocol, used instead of the PHP worker (server.command: rr worker-stub)",
+		Hidden: true,
+		RunE: func(*cobra.Command, []string) error {
+			const op = errors.Op("rr_worker_stub")
+
+			if grow != "" {
+				size, err := humanize.ParseBytes(grow)
+				if err != nil {
+					return errors.E(op, fmt.Errorf("invalid --grow value: %w", err))
+				}
+
+				o.grow = int(size)
+			}
+
+			s, err := newStub(o)
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			rl, err := worker.NewRelay()
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			if err = worker.Serve(rl, s.handle); err != nil {
+				return errors.E(op, err)
+			}
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&o.mode, "mode", modeEcho, "response mode: echo (request body), fixed (--response with --status)")
+	f.StringVar(&o.response, "response", "OK", "response body of the fixed mode")
+	f.IntVar(&o.status, "status", 200, "HTTP status of the fixed mode")
+	f.DurationVar(&o.latency, "latency", 0, "delay before every response")
+	f.DurationVar(&o.jitter, "jitter", 0, "random delay added to the latency, up to the value")
+	f.Float64Var(&o.errorRate, "error-rate", 0, "share of requests failed with the worker error (0..1)")
+	f.StringVar(&grow, "grow", "", "memory retained by every request (64KB, 1MB), reproduces memory leaks")
+	f.IntVar(&o.crashAfter, "crash-after", 0, "exit with the code 1 on the request after N served requests (0 never)")
+
+	return cmd
+}
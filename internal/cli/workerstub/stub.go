@@ -0,0 +1,103 @@
+package workerstub
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/pkg/worker"
+)
+
+const (
+	modeEcho  string = "echo"
+	modeFixed string = "fixed"
+)
+
+type options struct {
+	mode       string
+	response   string
+	status     int
+	latency    time.Duration
+	jitter     time.Duration
+	errorRate  float64
+	grow       int
+	crashAfter int
+}
+
+// stub is the worker handler, requests are handled one at a time.
+type stub struct {
+	opts *options
+	// http payloads are encoded for the http plugin, other payloads are returned as is
+	http   bool
+	rnd    *rand.Rand
+	served int
+	leak   [][]byte
+	sleep  func(time.Duration)
+	exit   func(code int)
+}
+
+func newStub(o *options) (*stub, error) {
+	if o.mode != modeEcho && o.mode != modeFixed {
+		return nil, fmt.Errorf("unknown mode `%s` (allowed: %s, %s)", o.mode, modeEcho, modeFixed)
+	}
+
+	if o.errorRate < 0 || o.errorRate > 1 {
+		return nil, fmt.Errorf("error rate should be in the 0..1 range, got %v", o.errorRate)
+	}
+
+	return &stub{
+		opts:  o,
+		http:  os.Getenv(worker.EnvMode) == "http",
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		sleep: time.Sleep,
+		exit:  os.Exit,
+	}, nil
+}
+
+func (s *stub) handle(p *worker.Payload) (*worker.Payload, error) {
+	if s.opts.crashAfter > 0 && s.served >= s.opts.crashAfter {
+		s.exit(1)
+	}
+
+	s.served++
+
+	if s.opts.grow > 0 {
+		b := make([]byte, s.opts.grow)
+		// touch the pages, so they are counted in RSS
+		for i := 0; i < len(b); i += 4096 {
+			b[i] = 1
+		}
+
+		s.leak = append(s.leak, b)
+	}
+
+	delay := s.opts.latency
+	if s.opts.jitter > 0 {
+		delay += time.Duration(s.rnd.Int63n(int64(s.opts.jitter)))
+	}
+
+	if delay > 0 {
+		s.sleep(delay)
+	}
+
+	if s.opts.errorRate > 0 && s.rnd.Float64() < s.opts.errorRate {
+		return nil, fmt.Errorf("worker-stub: random error (request %d)", s.served)
+	}
+
+	body, status := p.Body, http.StatusOK
+	if s.opts.mode == modeFixed {
+		body, status = []byte(s.opts.response), s.opts.status
+	}
+
+	if !s.http {
+		if s.opts.mode == modeFixed {
+			return &worker.Payload{Body: body}, nil
+		}
+
+		return p, nil
+	}
+
+	return worker.HTTPResponse(status, http.Header{}, body)
+}
@@ -0,0 +1,87 @@
+package workerstub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/pkg/worker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCommand(t *testing.T) {
+	cmd := NewCommand()
+
+	assert.Equal(t, "worker-stub", cmd.Use)
+	assert.True(t, cmd.Hidden)
+
+	for _, name := range []string{"mode", "response", "status", "latency", "jitter", "error-rate", "grow", "crash-after"} {
+		assert.NotNil(t, cmd.Flag(name), name)
+	}
+}
+
+func TestStub_Modes(t *testing.T) {
+	t.Setenv(worker.EnvMode, "http")
+
+	s, err := newStub(&options{mode: modeEcho})
+	require.NoError(t, err)
+
+	rsp, err := s.handle(&worker.Payload{Context: []byte(`{}`), Body: []byte("hello")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":200,"headers":{}}`, string(rsp.Context))
+	assert.Equal(t, "hello", string(rsp.Body))
+
+	s, err = newStub(&options{mode: modeFixed, response: "fixed", status: 418})
+	require.NoError(t, err)
+
+	rsp, err = s.handle(&worker.Payload{Body: []byte("hello")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":418,"headers":{}}`, string(rsp.Context))
+	assert.Equal(t, "fixed", string(rsp.Body))
+
+	t.Setenv(worker.EnvMode, "jobs")
+
+	s, err = newStub(&options{mode: modeEcho})
+	require.NoError(t, err)
+
+	rsp, err = s.handle(&worker.Payload{Context: []byte(`{"id":"1"}`), Body: []byte("hello")})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"1"}`, string(rsp.Context))
+
+	_, err = newStub(&options{mode: "unknown"})
+	assert.Error(t, err)
+
+	_, err = newStub(&options{mode: modeEcho, errorRate: 2})
+	assert.Error(t, err)
+}
+
+func TestStub_Faults(t *testing.T) {
+	s, err := newStub(&options{mode: modeEcho, latency: time.Second, jitter: time.Millisecond, grow: 8192, crashAfter: 2})
+	require.NoError(t, err)
+
+	var slept time.Duration
+	s.sleep = func(d time.Duration) { slept = d }
+
+	exited := -1
+	s.exit = func(code int) { exited = code }
+
+	for i := 0; i < 2; i++ {
+		_, err = s.handle(&worker.Payload{})
+		require.NoError(t, err)
+	}
+
+	assert.GreaterOrEqual(t, slept, time.Second)
+	assert.Less(t, slept, time.Second+time.Millisecond)
+	assert.Len(t, s.leak, 2)
+	assert.Equal(t, -1, exited)
+
+	_, _ = s.handle(&worker.Payload{})
+	assert.Equal(t, 1, exited)
+
+	s, err = newStub(&options{mode: modeEcho, errorRate: 1})
+	require.NoError(t, err)
+
+	_, err = s.handle(&worker.Payload{})
+	assert.Error(t, err)
+}
@@ -12,6 +12,8 @@ type Config struct {
 	GracePeriod time.Duration
 	PrintGraph  bool
 	LogLevel    endure.Level
+	// DisabledPlugins are not registered in the container (by plugin name)
+	DisabledPlugins []string
 }
 
 const (
@@ -38,9 +40,10 @@ func NewConfig(cfgFile string) (*Config, error) {
 	}
 
 	rrCfgEndure := struct {
-		GracePeriod time.Duration `mapstructure:"grace_period"`
-		PrintGraph  bool          `mapstructure:"print_graph"`
-		LogLevel    string        `mapstructure:"log_level"`
+		GracePeriod     time.Duration `mapstructure:"grace_period"`
+		PrintGraph      bool          `mapstructure:"print_graph"`
+		LogLevel        string        `mapstructure:"log_level"`
+		DisabledPlugins []string      `mapstructure:"disabled_plugins"`
 	}{}
 
 	err = v.UnmarshalKey(endureKey, &rrCfgEndure)
@@ -62,9 +65,10 @@ func NewConfig(cfgFile string) (*Config, error) {
 	}
 
 	return &Config{
-		GracePeriod: rrCfgEndure.GracePeriod,
-		PrintGraph:  rrCfgEndure.PrintGraph,
-		LogLevel:    logLevel,
+		GracePeriod:     rrCfgEndure.GracePeriod,
+		PrintGraph:      rrCfgEndure.PrintGraph,
+		LogLevel:        logLevel,
+		DisabledPlugins: rrCfgEndure.DisabledPlugins,
 	}, nil
 }
 
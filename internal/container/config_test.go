@@ -67,3 +67,11 @@ func TestNewConfig_LoggingLevels(t *testing.T) {
 		})
 	}
 }
+
+func TestNewConfig_DisabledPlugins(t *testing.T) {
+	c, err := container.NewConfig("test/endure_disabled_plugins.yaml")
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+
+	assert.Equal(t, []string{"temporal", "new_relic"}, c.DisabledPlugins)
+}
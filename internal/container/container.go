@@ -1,5 +1,7 @@
 package container
 
+//go:generate go run ./gen -manifest plugins.yaml -out plugins.go
+
 import (
 	endure "github.com/roadrunner-server/endure/pkg/container"
 )
@@ -18,3 +20,46 @@ func NewContainer(cfg Config) (*endure.Endure, error) {
 
 	return endure.NewContainer(nil, endureOptions...)
 }
+
+// pluginsAware is implemented by plugins resolving their dependencies against the registered plugins (external).
+type pluginsAware interface {
+	SetPlugins(names []string)
+}
+
+// Build creates endure container, registers the config plugin with the plugins and initializes them. Plugins are not
+// served yet, so network listeners are not bound.
+func Build(cfg *Config, configPlugin interface{}, plugins []interface{}) (*endure.Endure, error) {
+	c, err := NewContainer(*cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(plugins)+1)
+	names = append(names, PluginName(configPlugin))
+
+	for i := 0; i < len(plugins); i++ {
+		names = append(names, PluginName(plugins[i]))
+	}
+
+	for i := 0; i < len(plugins); i++ {
+		if p, ok := plugins[i].(pluginsAware); ok {
+			p.SetPlugins(names)
+		}
+	}
+
+	if err = c.Register(configPlugin); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(plugins); i++ {
+		if err = c.Register(plugins[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = c.Init(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
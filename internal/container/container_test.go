@@ -6,8 +6,10 @@ import (
 
 	"github.com/roadrunner-server/roadrunner/v2/internal/container"
 
+	"github.com/roadrunner-server/config/v2"
 	endure "github.com/roadrunner-server/endure/pkg/container"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewContainer(t *testing.T) { // there is no legal way to test container options
@@ -24,3 +26,23 @@ func TestNewContainer(t *testing.T) { // there is no legal way to test container
 	assert.NoError(t, err2)
 	assert.NotNil(t, c2)
 }
+
+// aware records the names of the registered plugins.
+type aware struct {
+	names []string
+}
+
+func (a *aware) Init() error { return nil }
+
+func (a *aware) Name() string { return "aware" }
+
+func (a *aware) SetPlugins(names []string) { a.names = names }
+
+func TestBuild_SetPlugins(t *testing.T) {
+	a := &aware{}
+
+	_, err := container.Build(&container.Config{GracePeriod: time.Second}, &config.Plugin{Type: "yaml",
+		ReadInCfg: []byte("version: '2.7'\n")}, []interface{}{a})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config", "aware"}, a.names)
+}
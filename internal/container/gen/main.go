@@ -0,0 +1,188 @@
+// Command gen generates Plugins() of the container package from the plugins manifest (plugins.yaml).
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// header records the manifest path relative to the generated file, so the tests regenerate it from the same manifest.
+const header = `// Code generated by internal/container/gen from %s. DO NOT EDIT.
+`
+
+const pluginsDoc = `
+// Plugins returns active plugins for the endure container. Feel free to add or remove any plugins in the
+// manifest and run ` + "`go generate`.\n"
+
+// Manifest lists the plugins of the generated file.
+type Manifest struct {
+	// Build constraint of the generated file (go:build expression), so binary variants can be generated from several
+	// manifests and selected with -tags.
+	Build   string   `mapstructure:"build"`
+	Plugins []Plugin `mapstructure:"plugins"`
+}
+
+// Plugin is the manifest entry.
+type Plugin struct {
+	// Import path of the plugin package.
+	Import string `mapstructure:"import"`
+	// Constructor is the expression creating the plugin, `&<package>.Plugin{}` by default. The package is imported
+	// with the name the constructor uses.
+	Constructor string `mapstructure:"constructor"`
+}
+
+func main() {
+	manifest := flag.String("manifest", "plugins.yaml", "plugins manifest")
+	out := flag.String("out", "plugins.go", "generated file")
+	flag.Parse()
+
+	m, err := Load(*manifest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	rel, err := relPath(*out, *manifest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	src, err := Generate(m, rel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err = os.WriteFile(*out, src, 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Load reads the manifest.
+func Load(path string) (*Manifest, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := v.Unmarshal(m); err != nil {
+		return nil, err
+	}
+
+	if len(m.Plugins) == 0 {
+		return nil, fmt.Errorf("manifest `%s` contains no plugins", path)
+	}
+
+	return m, nil
+}
+
+// relPath returns the manifest path relative to the directory of the generated file.
+func relPath(out, manifest string) (string, error) {
+	absOut, err := filepath.Abs(out)
+	if err != nil {
+		return "", err
+	}
+
+	absManifest, err := filepath.Abs(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(absOut), absManifest)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// Generate renders the container package file with the Plugins() function, manifest is recorded in the header.
+func Generate(m *Manifest, manifest string) ([]byte, error) {
+	version := regexp.MustCompile(`^v[0-9]+$`)
+	// package name used by the constructor: &http.Plugin{}, rrt.NewPlugin()
+	qualifier := regexp.MustCompile(`^&?([A-Za-z_][A-Za-z0-9_]*)\.`)
+
+	imports := make([]string, 0, len(m.Plugins))
+	names := make(map[string]string, len(m.Plugins))
+
+	var list bytes.Buffer
+
+	for i, p := range m.Plugins {
+		if p.Import == "" {
+			return nil, fmt.Errorf("plugin #%d has no import path", i)
+		}
+
+		// the last import path element without the major version
+		parts := strings.Split(p.Import, "/")
+		name := parts[len(parts)-1]
+
+		if version.MatchString(name) && len(parts) > 1 {
+			name = parts[len(parts)-2]
+		}
+
+		constructor := p.Constructor
+		if constructor == "" {
+			constructor = "&" + name + ".Plugin{}"
+		}
+
+		q := qualifier.FindStringSubmatch(constructor)
+		if q == nil {
+			return nil, fmt.Errorf("plugin `%s`: constructor `%s` does not use the package", p.Import, constructor)
+		}
+
+		if prev, ok := names[q[1]]; ok {
+			return nil, fmt.Errorf("plugins `%s` and `%s` have the same package name `%s`", prev, p.Import, q[1])
+		}
+
+		names[q[1]] = p.Import
+
+		if q[1] == name {
+			imports = append(imports, fmt.Sprintf("%q", p.Import))
+		} else {
+			imports = append(imports, fmt.Sprintf("%s %q", q[1], p.Import))
+		}
+
+		fmt.Fprintf(&list, "%s,\n", constructor)
+	}
+
+	sort.Slice(imports, func(i, j int) bool {
+		return importPath(imports[i]) < importPath(imports[j])
+	})
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, header, manifest)
+
+	if m.Build != "" {
+		fmt.Fprintf(&buf, "\n//go:build %s\n", m.Build)
+	}
+
+	fmt.Fprintf(&buf, "\npackage container\n\nimport (\n%s\n)\n", strings.Join(imports, "\n"))
+	buf.WriteString(pluginsDoc)
+	fmt.Fprintf(&buf, "func Plugins() []interface{} { //nolint:funlen\nreturn []interface{}{\n%s}\n}\n", list.String())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code: %w", err)
+	}
+
+	return src, nil
+}
+
+func importPath(spec string) string {
+	return spec[strings.Index(spec, `"`):]
+}
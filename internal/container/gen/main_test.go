@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_InSync regenerates every generated file of the container package from the manifest recorded in its
+// header, so slim builds and variants generated from other manifests pass as well.
+func TestGenerate_InSync(t *testing.T) {
+	files, err := filepath.Glob("../*.go")
+	require.NoError(t, err)
+
+	generated := regexp.MustCompile(`^// Code generated by internal/container/gen from (.+)\. DO NOT EDIT\.\n$`)
+	found := 0
+
+	for _, file := range files {
+		f, errO := os.Open(file)
+		require.NoError(t, errO)
+
+		line, _ := bufio.NewReader(f).ReadString('\n')
+		_ = f.Close()
+
+		m := generated.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		found++
+
+		manifest, errL := Load(filepath.Join("..", filepath.FromSlash(m[1])))
+		require.NoError(t, errL)
+
+		src, errG := Generate(manifest, m[1])
+		require.NoError(t, errG)
+
+		committed, errR := os.ReadFile(file)
+		require.NoError(t, errR)
+
+		assert.Equal(t, string(committed), string(src), "%s is outdated, run `go generate ./internal/container`", file)
+	}
+
+	assert.NotZero(t, found, "plugins.go has no generated header, run `go generate ./internal/container`")
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(&Manifest{
+		Build: "slim",
+		Plugins: []Plugin{
+			{Import: "github.com/roadrunner-server/server/v2"},
+			{Import: "github.com/roadrunner-server/new_relic/v2", Constructor: "&newrelic.Plugin{}"},
+			{Import: "github.com/temporalio/roadrunner-temporal", Constructor: "rrt.NewPlugin()"},
+		},
+	}, "slim.yaml")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(src),
+		"// Code generated by internal/container/gen from slim.yaml. DO NOT EDIT.\n\n//go:build slim\n\npackage container\n"))
+	// imports are sorted, named by the constructors
+	assert.Contains(t, string(src), "import (\n\tnewrelic \"github.com/roadrunner-server/new_relic/v2\"\n"+
+		"\t\"github.com/roadrunner-server/server/v2\"\n\trrt \"github.com/temporalio/roadrunner-temporal\"\n)")
+	assert.Contains(t, string(src), "\t\t&server.Plugin{},\n\t\t&newrelic.Plugin{},\n\t\trrt.NewPlugin(),\n")
+
+	// the last import path element is not an identifier, the constructor is required
+	_, err = Generate(&Manifest{Plugins: []Plugin{{Import: "github.com/temporalio/roadrunner-temporal"}}}, "a.yaml")
+	assert.Error(t, err)
+
+	_, err = Generate(&Manifest{Plugins: []Plugin{{Import: "a/http/v2"}, {Import: "b/http"}}}, "a.yaml")
+	assert.Error(t, err)
+
+	_, err = Generate(&Manifest{Plugins: []Plugin{{Constructor: "&foo.Plugin{}"}}}, "a.yaml")
+	assert.Error(t, err)
+
+	_, err = Generate(&Manifest{Plugins: []Plugin{{Import: "a/http", Constructor: "newPlugin()"}}}, "a.yaml")
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slim.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("build: slim\nplugins:\n  - import: a/server\n"+
+		"  - import: b/new_relic\n    constructor: \"&newrelic.Plugin{}\"\n"), 0o600))
+
+	m, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, &Manifest{Build: "slim", Plugins: []Plugin{
+		{Import: "a/server"},
+		{Import: "b/new_relic", Constructor: "&newrelic.Plugin{}"},
+	}}, m)
+}
+
+func TestRelPath(t *testing.T) {
+	rel, err := relPath("internal/container/plugins.go", "my-plugins.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "../../my-plugins.yaml", rel)
+
+	rel, err = relPath("plugins.go", "plugins.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "plugins.yaml", rel)
+}
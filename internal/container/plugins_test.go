@@ -6,6 +6,8 @@ import (
 )
 
 func TestPlugins(t *testing.T) {
+	names := make(map[string]struct{})
+
 	for _, p := range Plugins() {
 		if p == nil {
 			t.Error("plugin cannot be nil")
@@ -14,5 +16,17 @@ func TestPlugins(t *testing.T) {
 		if pk := reflect.TypeOf(p).Kind(); pk != reflect.Ptr && pk != reflect.Struct {
 			t.Errorf("plugin %v must be a structure or pointer to the structure", p)
 		}
+
+		if _, ok := p.(named); !ok {
+			t.Errorf("plugin %v must be named", p)
+
+			continue
+		}
+
+		if _, ok := names[PluginName(p)]; ok {
+			t.Errorf("plugin %s is registered twice", PluginName(p))
+		}
+
+		names[PluginName(p)] = struct{}{}
 	}
 }
@@ -0,0 +1,153 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// named is implemented by every endure plugin.
+type named interface {
+	Name() string
+}
+
+// provider is implemented by plugins providing additional dependencies (e.g. logger provides *zap.Logger).
+type provider interface {
+	Provides() []interface{}
+}
+
+// PluginName returns the name of the plugin used in the selection (`http`, `temporal`).
+func PluginName(p interface{}) string {
+	if n, ok := p.(named); ok {
+		return n.Name()
+	}
+
+	return reflect.TypeOf(p).String()
+}
+
+// Select filters plugins by name: when only is not empty, just those plugins are kept, then excluded ones are
+// removed. Names are validated against the plugins list. It is an error when a removed plugin provides an Init
+// dependency of a kept one and no kept plugin provides it.
+func Select(plugins []interface{}, only, exclude []string) ([]interface{}, error) {
+	if len(only) == 0 && len(exclude) == 0 {
+		return plugins, nil
+	}
+
+	known := make(map[string]struct{}, len(plugins))
+	for _, p := range plugins {
+		known[PluginName(p)] = struct{}{}
+	}
+
+	onlySet, err := nameSet(known, only)
+	if err != nil {
+		return nil, err
+	}
+
+	excludeSet, err := nameSet(known, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected, removed []interface{}
+
+	for _, p := range plugins {
+		name := PluginName(p)
+		_, in := onlySet[name]
+		_, out := excludeSet[name]
+
+		if (len(onlySet) > 0 && !in) || out {
+			removed = append(removed, p)
+
+			continue
+		}
+
+		selected = append(selected, p)
+	}
+
+	for _, p := range selected {
+		if err = checkDeps(p, selected, removed); err != nil {
+			return nil, err
+		}
+	}
+
+	return selected, nil
+}
+
+func nameSet(known map[string]struct{}, names []string) (map[string]struct{}, error) {
+	set := make(map[string]struct{}, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if _, ok := known[name]; !ok {
+			available := make([]string, 0, len(known))
+			for k := range known {
+				available = append(available, k)
+			}
+
+			sort.Strings(available)
+
+			return nil, fmt.Errorf("unknown plugin `%s`, available: %s", name, strings.Join(available, ", "))
+		}
+
+		set[name] = struct{}{}
+	}
+
+	return set, nil
+}
+
+// checkDeps verifies that every Init argument of the plugin, provided by a removed plugin, is still provided by
+// a selected one. Arguments provided by nobody in the list (e.g. the config plugin) are left to endure.
+func checkDeps(p interface{}, selected, removed []interface{}) error {
+	m, ok := reflect.TypeOf(p).MethodByName("Init")
+	if !ok {
+		return nil
+	}
+
+	// skip the receiver
+	for i := 1; i < m.Type.NumIn(); i++ {
+		dep := m.Type.In(i)
+
+		if len(providers(dep, selected)) > 0 {
+			continue
+		}
+
+		if names := providers(dep, removed); len(names) > 0 {
+			return fmt.Errorf("plugin `%s` requires %s provided by disabled plugin(s): %s",
+				PluginName(p), dep, strings.Join(names, ", "))
+		}
+	}
+
+	return nil
+}
+
+// providers returns names of the plugins providing the dependency: the plugin itself or its Provides functions.
+func providers(dep reflect.Type, plugins []interface{}) []string {
+	var names []string
+
+	for _, p := range plugins {
+		types := []reflect.Type{reflect.TypeOf(p)}
+
+		if pr, ok := p.(provider); ok {
+			for _, fn := range pr.Provides() {
+				if ft := reflect.TypeOf(fn); ft != nil && ft.Kind() == reflect.Func && ft.NumOut() > 0 {
+					types = append(types, ft.Out(0))
+				}
+			}
+		}
+
+		for _, t := range types {
+			if t == dep || (dep.Kind() == reflect.Interface && t.Implements(dep)) {
+				names = append(names, PluginName(p))
+
+				break
+			}
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,95 @@
+package container_test
+
+import (
+	"testing"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/container"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type log struct{}
+
+type loggerPlugin struct{}
+
+func (p *loggerPlugin) Init() error                  { return nil }
+func (p *loggerPlugin) Name() string                 { return "logger" }
+func (p *loggerPlugin) Provides() []interface{}      { return []interface{}{p.ServiceLogger} }
+func (p *loggerPlugin) ServiceLogger() (*log, error) { return &log{}, nil }
+
+type Server interface {
+	NewWorker() error
+}
+
+type serverPlugin struct{}
+
+func (p *serverPlugin) Init(*log) error  { return nil }
+func (p *serverPlugin) Name() string     { return "server" }
+func (p *serverPlugin) NewWorker() error { return nil }
+
+type httpPlugin struct{}
+
+func (p *httpPlugin) Init(*log, Server) error { return nil }
+func (p *httpPlugin) Name() string            { return "http" }
+
+type temporalPlugin struct{}
+
+func (p *temporalPlugin) Init(Server) error { return nil }
+func (p *temporalPlugin) Name() string      { return "temporal" }
+
+func names(plugins []interface{}) []string {
+	res := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		res = append(res, container.PluginName(p))
+	}
+
+	return res
+}
+
+func TestSelect(t *testing.T) {
+	all := []interface{}{&loggerPlugin{}, &serverPlugin{}, &httpPlugin{}, &temporalPlugin{}}
+
+	for _, tt := range []struct {
+		name        string
+		giveOnly    []string
+		giveExclude []string
+		want        []string
+		wantError   string
+	}{
+		{name: "all", want: []string{"logger", "server", "http", "temporal"}},
+		{name: "exclude", giveExclude: []string{"temporal"}, want: []string{"logger", "server", "http"}},
+		{name: "only", giveOnly: []string{"logger", "server", "temporal"}, want: []string{"logger", "server", "temporal"}},
+		{name: "only and exclude", giveOnly: []string{"logger", "server", "temporal"}, giveExclude: []string{"temporal"},
+			want: []string{"logger", "server"}},
+		{name: "unknown", giveExclude: []string{"foo"}, wantError: "unknown plugin `foo`"},
+		{name: "provided dependency", giveExclude: []string{"logger"},
+			wantError: "plugin `server` requires *container_test.log provided by disabled plugin(s): logger"},
+		{name: "interface dependency", giveOnly: []string{"logger", "http"},
+			wantError: "plugin `http` requires container_test.Server provided by disabled plugin(s): server"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := container.Select(all, tt.giveOnly, tt.giveExclude)
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(res))
+		})
+	}
+}
+
+func TestSelect_CompiledIn(t *testing.T) {
+	res, err := container.Select(container.Plugins(), nil, []string{"temporal", "new_relic"})
+	require.NoError(t, err)
+	assert.Len(t, res, len(container.Plugins())-2)
+
+	_, err = container.Select(container.Plugins(), nil, []string{"server"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "provided by disabled plugin(s): server")
+}
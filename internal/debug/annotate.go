@@ -0,0 +1,114 @@
+package debug
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// profile.proto field numbers (https://github.com/google/pprof/blob/main/proto/profile.proto)
+const (
+	fieldStringTable = 6
+	fieldComment     = 13
+
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// AddComment appends the comment to the gzipped pprof profile, it is shown by `go tool pprof -comments`. The
+// profile is not decoded: protobuf allows appending repeated fields, so only the string table size is counted.
+func AddComment(profile []byte, comment string) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(profile))
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := countField(raw, fieldStringTable)
+	if err != nil {
+		return nil, err
+	}
+
+	// string_table entry
+	raw = appendUvarint(raw, fieldStringTable<<3|wireBytes)
+	raw = appendUvarint(raw, uint64(len(comment)))
+	raw = append(raw, comment...)
+	// comment referencing the entry (non-packed encoding of the repeated int64)
+	raw = appendUvarint(raw, fieldComment<<3|wireVarint)
+	raw = appendUvarint(raw, uint64(idx))
+
+	var out bytes.Buffer
+
+	zw := gzip.NewWriter(&out)
+	if _, err = zw.Write(raw); err != nil {
+		return nil, err
+	}
+
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// countField counts top level occurrences of the field in the protobuf message.
+func countField(msg []byte, field uint64) (int, error) {
+	errMalformed := errors.New("malformed profile")
+	count := 0
+
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return 0, errMalformed
+		}
+
+		msg = msg[n:]
+
+		switch key & 7 {
+		case wireVarint:
+			_, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return 0, errMalformed
+			}
+		case wireFixed64:
+			n = 8
+		case wireFixed32:
+			n = 4
+		case wireBytes:
+			l, ln := binary.Uvarint(msg)
+			if ln <= 0 || uint64(len(msg)-ln) < l {
+				return 0, errMalformed
+			}
+
+			n = ln + int(l)
+		default:
+			return 0, errMalformed
+		}
+
+		if n > len(msg) {
+			return 0, errMalformed
+		}
+
+		if key>>3 == field {
+			count++
+		}
+
+		msg = msg[n:]
+	}
+
+	return count, nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
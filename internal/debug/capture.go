@@ -0,0 +1,68 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"strings"
+)
+
+const (
+	// ProfileCPU is the CPU profile, captured for the `seconds` duration.
+	ProfileCPU string = "profile"
+	// ProfileTrace is the execution trace, captured for the `seconds` duration.
+	ProfileTrace string = "trace"
+	// ProfileHeap is the heap profile.
+	ProfileHeap string = "heap"
+	// ProfileGoroutine is the goroutines profile (`debug=2` gives the full text dump).
+	ProfileGoroutine string = "goroutine"
+)
+
+// Capture writes the named profile to w using the same handlers as the /debug/pprof/ endpoints. Params are the
+// handler's query parameters (seconds, debug, gc).
+func Capture(ctx context.Context, w io.Writer, profile string, params url.Values) error {
+	var h http.Handler
+
+	switch profile {
+	case ProfileCPU:
+		h = http.HandlerFunc(pprof.Profile)
+	case ProfileTrace:
+		h = http.HandlerFunc(pprof.Trace)
+	default:
+		h = pprof.Handler(profile)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/debug/pprof/"+profile+"?"+params.Encode(), http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	rw := &bufferWriter{header: make(http.Header), status: http.StatusOK}
+	h.ServeHTTP(rw, req)
+
+	if rw.status != http.StatusOK {
+		return fmt.Errorf("capture %s profile: %d %s", profile, rw.status, strings.TrimSpace(rw.buf.String()))
+	}
+
+	_, err = rw.buf.WriteTo(w)
+
+	return err
+}
+
+// bufferWriter is a minimal in-memory http.ResponseWriter, the body is written to the destination only when the
+// handler succeeded.
+type bufferWriter struct {
+	header http.Header
+	status int
+	buf    bytes.Buffer
+}
+
+func (b *bufferWriter) Header() http.Header { return b.header }
+
+func (b *bufferWriter) Write(p []byte) (int, error) { return b.buf.Write(p) }
+
+func (b *bufferWriter) WriteHeader(status int) { b.status = status }
@@ -0,0 +1,221 @@
+package debug
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	debugKey       = "debug"
+	defaultAddress = "127.0.0.1:6061"
+
+	defaultCPUDuration = time.Second * 10
+	defaultMaxFiles    = 100
+	defaultMaxSize     = 100
+
+	defaultTraceDuration    = time.Second * 5
+	defaultWatchdogInterval = time.Second * 5
+	defaultWatchdogCooldown = time.Minute * 5
+)
+
+// Config is the debug server configuration (the `debug` section of the .rr.yaml).
+type Config struct {
+	// Address to listen on, localhost only by default
+	Address string `mapstructure:"address"`
+	// BasicAuth protects the endpoints when username or password is set
+	BasicAuth struct {
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"basic_auth"`
+	// BlockProfileRate is passed to the runtime.SetBlockProfileRate, 0 disables block profiling
+	BlockProfileRate int `mapstructure:"block_profile_rate"`
+	// MutexProfileFraction is passed to the runtime.SetMutexProfileFraction, 0 disables mutex profiling
+	MutexProfileFraction int `mapstructure:"mutex_profile_fraction"`
+	// TraceDuration is the duration of the execution trace recorded on SIGUSR2
+	TraceDuration time.Duration `mapstructure:"trace_duration"`
+	// Profiling is the continuous profiling to disk, works without the debug server
+	Profiling ProfilingConfig `mapstructure:"profiling"`
+	// Watchdog captures profiles when RoadRunner process resources thresholds are crossed
+	Watchdog WatchdogConfig `mapstructure:"watchdog"`
+}
+
+// ProfilingConfig configures continuous profiling.
+type ProfilingConfig struct {
+	// Interval between captures, 0 disables continuous profiling
+	Interval time.Duration `mapstructure:"interval"`
+	// Dir to write profiles to
+	Dir string `mapstructure:"dir"`
+	// Profiles to capture: cpu, heap, goroutine, trace
+	Profiles []string `mapstructure:"profiles"`
+	// CPUDuration is the duration of the CPU profile (and execution trace)
+	CPUDuration time.Duration `mapstructure:"cpu_duration"`
+	// MaxFiles to keep in the directory
+	MaxFiles int `mapstructure:"max_files"`
+	// MaxSize of all profiles in the directory, megabytes
+	MaxSize int `mapstructure:"max_size"`
+	// FilePrefix of the written files, only they are rotated (PrefixProfile by default)
+	FilePrefix string `mapstructure:"-"`
+}
+
+// NewConfig reads debug server configuration. Missing section is not an error, defaults are used instead.
+func NewConfig(cfgFile string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(cfgFile)
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+
+	if v.IsSet(debugKey) {
+		err = v.UnmarshalKey(debugKey, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.InitDefaults()
+
+	err = cfg.Profiling.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// InitDefaults sets default values and expands ${ENV} references in the credentials.
+func (c *Config) InitDefaults() {
+	if c.Address == "" {
+		c.Address = defaultAddress
+	}
+
+	c.BasicAuth.Username = os.ExpandEnv(c.BasicAuth.Username)
+	c.BasicAuth.Password = os.ExpandEnv(c.BasicAuth.Password)
+
+	if c.TraceDuration == 0 {
+		c.TraceDuration = defaultTraceDuration
+	}
+
+	c.Profiling.InitDefaults()
+	c.Watchdog.InitDefaults()
+}
+
+// WatchdogConfig configures the resources watchdog. Zero thresholds are disabled.
+type WatchdogConfig struct {
+	// Interval between samples
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxHeap is the Go heap threshold, megabytes
+	MaxHeap int `mapstructure:"max_heap"`
+	// MaxRSS is the process resident set size threshold, megabytes
+	MaxRSS int `mapstructure:"max_rss"`
+	// MaxGoroutines is the goroutines count threshold
+	MaxGoroutines int `mapstructure:"max_goroutines"`
+	// Cooldown is the minimal interval between captures
+	Cooldown time.Duration `mapstructure:"cooldown"`
+	// Dir to write profiles to
+	Dir string `mapstructure:"dir"`
+	// MaxFiles to keep in the directory
+	MaxFiles int `mapstructure:"max_files"`
+	// MaxSize of all profiles in the directory, megabytes
+	MaxSize int `mapstructure:"max_size"`
+}
+
+// Enabled reports whether at least one threshold is set.
+func (c *WatchdogConfig) Enabled() bool {
+	return c.MaxHeap > 0 || c.MaxRSS > 0 || c.MaxGoroutines > 0
+}
+
+// InitDefaults sets watchdog defaults.
+func (c *WatchdogConfig) InitDefaults() {
+	if c.Interval == 0 {
+		c.Interval = defaultWatchdogInterval
+	}
+
+	if c.Cooldown == 0 {
+		c.Cooldown = defaultWatchdogCooldown
+	}
+
+	if c.Dir == "" {
+		c.Dir = filepath.Join(os.TempDir(), "rr-watchdog")
+	}
+
+	if c.MaxFiles == 0 {
+		c.MaxFiles = defaultMaxFiles
+	}
+
+	if c.MaxSize == 0 {
+		c.MaxSize = defaultMaxSize
+	}
+}
+
+// InitDefaults sets profiling defaults, `cpu` profile name is mapped to the pprof `profile` handler.
+func (c *ProfilingConfig) InitDefaults() {
+	if c.Dir == "" {
+		c.Dir = filepath.Join(os.TempDir(), "rr-profiles")
+	}
+
+	if len(c.Profiles) == 0 {
+		c.Profiles = []string{"cpu", ProfileHeap, ProfileGoroutine}
+	}
+
+	for i := 0; i < len(c.Profiles); i++ {
+		if c.Profiles[i] == "cpu" {
+			c.Profiles[i] = ProfileCPU
+		}
+	}
+
+	if c.CPUDuration == 0 {
+		c.CPUDuration = defaultCPUDuration
+	}
+
+	if c.MaxFiles == 0 {
+		c.MaxFiles = defaultMaxFiles
+	}
+
+	if c.MaxSize == 0 {
+		c.MaxSize = defaultMaxSize
+	}
+}
+
+// Valid validates profiling configuration.
+func (c *ProfilingConfig) Valid() error {
+	if c.Interval == 0 {
+		return nil
+	}
+
+	for _, p := range c.Profiles {
+		if (p == ProfileCPU || p == ProfileTrace) && c.Interval <= c.CPUDuration {
+			return fmt.Errorf("profiling interval (%s) should be greater than cpu_duration (%s)", c.Interval, c.CPUDuration)
+		}
+	}
+
+	return nil
+}
+
+// Options returns server options based on the configuration.
+func (c *Config) Options() []Option {
+	if c.BasicAuth.Username == "" && c.BasicAuth.Password == "" {
+		return nil
+	}
+
+	return []Option{WithBasicAuth(c.BasicAuth.Username, c.BasicAuth.Password)}
+}
+
+// ApplyProfileRates sets the block and mutex profiling rates of the whole process.
+func (c *Config) ApplyProfileRates() {
+	if c.BlockProfileRate > 0 {
+		runtime.SetBlockProfileRate(c.BlockProfileRate)
+	}
+
+	if c.MutexProfileFraction > 0 {
+		runtime.SetMutexProfileFraction(c.MutexProfileFraction)
+	}
+}
@@ -0,0 +1,234 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// PrefixProfile is the file name prefix of the continuous profiling captures.
+	PrefixProfile = "rr-profile-"
+	// PrefixWatchdog is the file name prefix of the watchdog captures.
+	PrefixWatchdog = "rr-watchdog-"
+	// PrefixSignal is the file name prefix of the captures requested with the signals.
+	PrefixSignal = "rr-signal-"
+
+	timeLayout = "20060102T150405.000"
+)
+
+// Profiler periodically captures profiles into the rotating directory.
+type Profiler struct {
+	cfg     *ProfilingConfig
+	version string
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewProfiler creates continuous profiler, version is written into every captured profile.
+func NewProfiler(cfg *ProfilingConfig, version string) *Profiler {
+	if cfg.FilePrefix == "" {
+		cfg.FilePrefix = PrefixProfile
+	}
+
+	return &Profiler{
+		cfg:     cfg,
+		version: version,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start capturing profiles in background.
+func (p *Profiler) Start() {
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		tt := time.NewTicker(p.cfg.Interval)
+		defer tt.Stop()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-tt.C:
+				ctx, cancel := context.WithCancel(context.Background())
+				go func() {
+					select {
+					case <-p.stop:
+						cancel()
+					case <-ctx.Done():
+					}
+				}()
+
+				if _, err := p.CaptureAll(ctx, ""); err != nil {
+					log.Printf("continuous profiling: %v", err)
+				}
+
+				cancel()
+			}
+		}
+	}()
+}
+
+// Stop capturing, an in-flight CPU profile is interrupted.
+func (p *Profiler) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// CaptureAll captures every configured profile once, rotates the profiler files in the directory and returns written
+// file names. Reason (if any) is added to the file names and metadata.
+func (p *Profiler) CaptureAll(ctx context.Context, reason string) ([]string, error) {
+	if err := os.MkdirAll(p.cfg.Dir, 0o755); err != nil { //nolint:gosec
+		return nil, err
+	}
+
+	var (
+		files []string
+		errs  []string
+	)
+
+	for _, name := range p.cfg.Profiles {
+		file, err := p.capture(ctx, name, reason)
+		if err != nil {
+			errs = append(errs, err.Error())
+
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	if err := Rotate(p.cfg.Dir, p.cfg.FilePrefix, p.cfg.MaxFiles, int64(p.cfg.MaxSize)<<20); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return files, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return files, nil
+}
+
+func (p *Profiler) capture(ctx context.Context, name, reason string) (string, error) {
+	params := url.Values{}
+	ext := ".pb.gz"
+
+	// pprof handlers treat 0 seconds as the default (30s)
+	seconds := "1"
+	if s := int(p.cfg.CPUDuration.Seconds()); s > 1 {
+		seconds = strconv.Itoa(s)
+	}
+
+	switch name {
+	case ProfileCPU:
+		params.Set("seconds", seconds)
+	case ProfileGoroutine:
+		// full text dump, readable without tools
+		params.Set("debug", "2")
+		ext = ".txt"
+	case ProfileTrace:
+		params.Set("seconds", seconds)
+		ext = ".trace"
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Capture(ctx, buf, name, params); err != nil {
+		return "", err
+	}
+
+	meta := "roadrunner version: " + p.version
+	if reason != "" {
+		meta += ", reason: " + reason
+	}
+
+	data := buf.Bytes()
+
+	switch ext {
+	case ".pb.gz":
+		annotated, err := AddComment(data, meta)
+		if err != nil {
+			return "", err
+		}
+
+		data = annotated
+	case ".txt":
+		data = append([]byte("# "+meta+"\n\n"), data...)
+	}
+
+	parts := []string{p.cfg.FilePrefix + p.version, name}
+	if reason != "" {
+		parts = append(parts, reason)
+	}
+
+	parts = append(parts, time.Now().Format(timeLayout))
+	file := filepath.Join(p.cfg.Dir, strings.Join(parts, "-")+ext)
+
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
+
+// Rotate removes the oldest profiles with the file name prefix from the directory while there are more than maxFiles
+// of them or their total size exceeds maxSize bytes. Zero disables the corresponding limit.
+func Rotate(dir, prefix string, maxFiles int, maxSize int64) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var (
+		files []file
+		total int64
+	)
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+
+		info, errI := e.Info()
+		if errI != nil {
+			continue
+		}
+
+		files = append(files, file{path: filepath.Join(dir, e.Name()), size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	// newest first
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	for len(files) > 0 && ((maxFiles > 0 && len(files) > maxFiles) || (maxSize > 0 && total > maxSize)) {
+		last := files[len(files)-1]
+
+		if err = os.Remove(last.path); err != nil {
+			return err
+		}
+
+		total -= last.size
+		files = files[:len(files)-1]
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package debug_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/debug"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureAndAddComment(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, debug.Capture(context.Background(), buf, debug.ProfileHeap, url.Values{}))
+
+	out, err := debug.AddComment(buf.Bytes(), "roadrunner version: 2.11.0")
+	require.NoError(t, err)
+
+	zr, err := gzip.NewReader(bytes.NewReader(out))
+	require.NoError(t, err)
+
+	raw, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "roadrunner version: 2.11.0")
+
+	_, err = debug.AddComment([]byte("not a profile"), "foo")
+	assert.Error(t, err)
+
+	assert.Error(t, debug.Capture(context.Background(), buf, "foobar", url.Values{}))
+}
+
+func TestProfiler_CaptureAllAndRotate(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := &debug.ProfilingConfig{Dir: dir, Profiles: []string{debug.ProfileHeap, debug.ProfileGoroutine}, MaxFiles: 3}
+	cfg.InitDefaults()
+
+	p := debug.NewProfiler(cfg, "2.11.0")
+
+	files, err := p.CaptureAll(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	assert.True(t, strings.HasPrefix(filepath.Base(files[0]), "rr-profile-2.11.0-heap-"))
+	assert.True(t, strings.HasSuffix(files[0], ".pb.gz"))
+
+	dump, err := os.ReadFile(files[1])
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(dump), "# roadrunner version: 2.11.0\n"))
+	assert.Contains(t, string(dump), "goroutine ")
+
+	time.Sleep(time.Millisecond * 10)
+
+	_, err = p.CaptureAll(context.Background(), "manual")
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	// files not created by the profiler, watchdog captures included, are never removed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo.txt"), []byte("foo"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, debug.PrefixWatchdog+"2.11.0-heap.pb.gz"), nil, 0o600))
+	require.NoError(t, debug.Rotate(dir, debug.PrefixProfile, 1, 0))
+
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestProfilingConfig_Valid(t *testing.T) {
+	cfg := &debug.ProfilingConfig{Interval: time.Second * 5}
+	cfg.InitDefaults()
+
+	assert.Equal(t, []string{debug.ProfileCPU, debug.ProfileHeap, debug.ProfileGoroutine}, cfg.Profiles)
+	assert.Error(t, cfg.Valid())
+
+	cfg.Interval = time.Minute
+	assert.NoError(t, cfg.Valid())
+}
+
+func TestWatchdog_Check(t *testing.T) {
+	cfg := &debug.WatchdogConfig{Dir: t.TempDir(), MaxGoroutines: 100_000, MaxHeap: 1 << 20}
+	cfg.InitDefaults()
+
+	w := debug.NewWatchdog(cfg, "2.11.0")
+
+	s := w.Sample()
+	assert.Greater(t, s.Heap, uint64(0))
+	assert.Greater(t, s.Goroutines, 0)
+
+	files, reason, err := w.Check(s)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+	assert.Empty(t, files)
+
+	s.Goroutines = 100_001
+
+	files, reason, err = w.Check(s)
+	require.NoError(t, err)
+	assert.Equal(t, "goroutines-100001", reason)
+	require.Len(t, files, 2)
+	assert.True(t, strings.HasPrefix(filepath.Base(files[0]), debug.PrefixWatchdog))
+	assert.Contains(t, filepath.Base(files[0]), "-heap-goroutines-100001-")
+
+	// cooldown
+	files, reason, err = w.Check(s)
+	require.NoError(t, err)
+	assert.NotEmpty(t, reason)
+	assert.Empty(t, files)
+}
@@ -2,8 +2,13 @@ package debug
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"net"
 	"net/http"
 	"net/http/pprof"
+	"runtime/metrics"
 )
 
 // Server is a HTTP server for debugging.
@@ -11,8 +16,29 @@ type Server struct {
 	srv *http.Server
 }
 
+// Option configures debug server.
+type Option func(s *options)
+
+type options struct {
+	username string
+	password string
+}
+
+// WithBasicAuth protects all debug endpoints with the HTTP basic authentication.
+func WithBasicAuth(username, password string) Option {
+	return func(o *options) {
+		o.username = username
+		o.password = password
+	}
+}
+
 // NewServer creates new HTTP server for debugging.
-func NewServer() Server {
+func NewServer(opts ...Option) Server {
+	o := &options{}
+	for i := 0; i < len(opts); i++ {
+		opts[i](o)
+	}
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -20,8 +46,15 @@ func NewServer() Server {
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/metrics", runtimeMetrics)
 
-	return Server{srv: &http.Server{Handler: mux}}
+	var handler http.Handler = mux
+	if o.username != "" || o.password != "" {
+		handler = basicAuth(o.username, o.password, mux)
+	}
+
+	return Server{srv: &http.Server{Handler: handler}}
 }
 
 // Start debug server.
@@ -31,7 +64,57 @@ func (s *Server) Start(addr string) error {
 	return s.srv.ListenAndServe()
 }
 
+// Serve debug server on the already bound listener. Use it to report bind errors before going into the background.
+func (s *Server) Serve(l net.Listener) error {
+	s.srv.Addr = l.Addr().String()
+
+	return s.srv.Serve(l)
+}
+
 // Stop debug server.
 func (s *Server) Stop(ctx context.Context) error {
 	return s.srv.Shutdown(ctx)
 }
+
+func basicAuth(username, password string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(u), []byte(username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="roadrunner debug"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// runtimeMetrics writes all supported runtime/metrics samples as JSON object (histograms are skipped).
+func runtimeMetrics(w http.ResponseWriter, _ *http.Request) {
+	descs := metrics.All()
+	samples := make([]metrics.Sample, len(descs))
+
+	for i := 0; i < len(descs); i++ {
+		samples[i].Name = descs[i].Name
+	}
+
+	metrics.Read(samples)
+
+	out := make(map[string]any, len(samples))
+
+	for i := 0; i < len(samples); i++ {
+		switch samples[i].Value.Kind() { //nolint:exhaustive
+		case metrics.KindUint64:
+			out[samples[i].Name] = samples[i].Value.Uint64()
+		case metrics.KindFloat64:
+			out[samples[i].Name] = samples[i].Value.Float64()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
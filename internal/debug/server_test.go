@@ -55,3 +55,64 @@ func TestServer_StartingAndStopping(t *testing.T) {
 		cancel()
 	}
 }
+
+func TestServer_BasicAuthAndEndpoints(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	s := debug.NewServer(debug.WithBasicAuth("rr", "secret"))
+
+	go func() { assert.ErrorIs(t, s.Serve(l), http.ErrServerClosed) }()
+
+	defer func() { assert.NoError(t, s.Stop(context.Background())) }()
+
+	for _, tt := range []struct {
+		uri      string
+		user     string
+		password string
+		want     int
+	}{
+		{uri: "/debug/pprof/", want: http.StatusUnauthorized},
+		{uri: "/debug/pprof/", user: "rr", password: "wrong", want: http.StatusUnauthorized},
+		{uri: "/debug/pprof/", user: "rr", password: "secret", want: http.StatusOK},
+		{uri: "/debug/vars", user: "rr", password: "secret", want: http.StatusOK},
+		{uri: "/debug/metrics", user: "rr", password: "secret", want: http.StatusOK},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+l.Addr().String()+tt.uri, http.NoBody)
+		if tt.user != "" {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, resp.StatusCode, tt.uri)
+
+		_ = resp.Body.Close()
+
+		cancel()
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	t.Setenv("RR_DEBUG_PASSWORD", "secret")
+
+	c, err := debug.NewConfig("test/debug_ok.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "127.0.0.1:6062", c.Address)
+	assert.Equal(t, "rr", c.BasicAuth.Username)
+	assert.Equal(t, "secret", c.BasicAuth.Password)
+	assert.Equal(t, 1, c.BlockProfileRate)
+	assert.Equal(t, 5, c.MutexProfileFraction)
+	assert.Len(t, c.Options(), 1)
+
+	c, err = debug.NewConfig("test/debug_empty.yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "127.0.0.1:6061", c.Address)
+	assert.Empty(t, c.Options())
+	// watchdog captures are not rotated away by the continuous profiling
+	assert.NotEqual(t, c.Profiling.Dir, c.Watchdog.Dir)
+}
@@ -0,0 +1,155 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+const (
+	heapMetric = "/memory/classes/heap/objects:bytes"
+)
+
+// Sample is the resources usage of the RoadRunner process itself (not its workers).
+type Sample struct {
+	// Heap is the memory occupied by live and not yet swept heap objects, bytes
+	Heap uint64
+	// Goroutines count
+	Goroutines int
+	// RSS is the resident set size of the process, bytes (0 when unavailable)
+	RSS uint64
+}
+
+// Watchdog samples RoadRunner process resources and captures heap profile and goroutine dump when one of the
+// thresholds is crossed.
+type Watchdog struct {
+	cfg      *WatchdogConfig
+	profiler *Profiler
+	proc     *process.Process
+
+	mu   sync.Mutex
+	last time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewWatchdog creates resources watchdog, version is written into every captured profile.
+func NewWatchdog(cfg *WatchdogConfig, version string) *Watchdog {
+	proc, err := process.NewProcess(int32(os.Getpid())) //nolint:gosec
+	if err != nil {
+		proc = nil
+	}
+
+	return &Watchdog{
+		cfg: cfg,
+		profiler: NewProfiler(&ProfilingConfig{
+			Dir:        cfg.Dir,
+			Profiles:   []string{ProfileHeap, ProfileGoroutine},
+			MaxFiles:   cfg.MaxFiles,
+			MaxSize:    cfg.MaxSize,
+			FilePrefix: PrefixWatchdog,
+		}, version),
+		proc: proc,
+		stop: make(chan struct{}),
+	}
+}
+
+// Start sampling in background.
+func (w *Watchdog) Start() {
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		tt := time.NewTicker(w.cfg.Interval)
+		defer tt.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-tt.C:
+				files, reason, err := w.Check(w.Sample())
+				if err != nil {
+					log.Printf("watchdog: %s: %v", reason, err)
+				}
+
+				if len(files) > 0 {
+					log.Printf("watchdog: %s, profiles written: %v", reason, files)
+				}
+			}
+		}
+	}()
+}
+
+// Stop sampling.
+func (w *Watchdog) Stop() {
+	close(w.stop)
+	w.wg.Wait()
+}
+
+// Sample reads current resources usage.
+func (w *Watchdog) Sample() Sample {
+	s := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(s)
+
+	smp := Sample{Goroutines: runtime.NumGoroutine()}
+
+	if s[0].Value.Kind() == metrics.KindUint64 {
+		smp.Heap = s[0].Value.Uint64()
+	}
+
+	if w.proc != nil {
+		if mi, err := w.proc.MemoryInfo(); err == nil {
+			smp.RSS = mi.RSS
+		}
+	}
+
+	return smp
+}
+
+// Check compares the sample with the thresholds and captures profiles when any of them is crossed, unless the
+// previous capture happened less than cooldown ago. Returned reason describes the crossed threshold.
+func (w *Watchdog) Check(s Sample) ([]string, string, error) {
+	reason := w.exceeded(s)
+	if reason == "" {
+		return nil, "", nil
+	}
+
+	w.mu.Lock()
+	if !w.last.IsZero() && time.Since(w.last) < w.cfg.Cooldown {
+		w.mu.Unlock()
+
+		return nil, reason, nil
+	}
+
+	w.last = time.Now()
+	w.mu.Unlock()
+
+	files, err := w.profiler.CaptureAll(context.Background(), reason)
+
+	return files, reason, err
+}
+
+func (w *Watchdog) exceeded(s Sample) string {
+	const mb = 1 << 20
+
+	switch {
+	case w.cfg.MaxHeap > 0 && s.Heap > uint64(w.cfg.MaxHeap)*mb:
+		return fmt.Sprintf("heap-%dMB", s.Heap/mb)
+	case w.cfg.MaxRSS > 0 && s.RSS > uint64(w.cfg.MaxRSS)*mb:
+		return fmt.Sprintf("rss-%dMB", s.RSS/mb)
+	case w.cfg.MaxGoroutines > 0 && s.Goroutines > w.cfg.MaxGoroutines:
+		return fmt.Sprintf("goroutines-%d", s.Goroutines)
+	default:
+		return ""
+	}
+}
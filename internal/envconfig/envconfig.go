@@ -0,0 +1,459 @@
+// Package envconfig builds the RoadRunner configuration from the RR_ prefixed environment variables, so RoadRunner
+// runs without the configuration file.
+//
+// The variable name is the configuration key in upper case with the dots replaced by underscores and the RR_ prefix
+// (http.pool.num_workers - RR_HTTP_POOL_NUM_WORKERS). Names of the user-defined map entries (kv storages, jobs
+// pipelines, log channels, etc.) are written in place (RR_KV_<NAME>_DRIVER), list items are addressed by the index
+// (RR_EXTERNAL_PLUGINS_<N>_NAME). Lists of values are comma-separated or written in the YAML flow form ("[a, b]").
+// Values are parsed as YAML scalars. The available keys are generated from the reference configuration (.rr.yaml).
+package envconfig
+
+//go:generate go run ./gen -config ../../.rr.yaml -out keys.go
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Prefix of the configuration variables.
+	Prefix string = "RR_"
+	// ConfigVersion is the configuration version used when RR_VERSION is not set.
+	ConfigVersion string = "2.7"
+	// placeholders in the variable names
+	namePlaceholder  string = "<NAME>"
+	keyPlaceholder   string = "<KEY>"
+	indexPlaceholder string = "<N>"
+)
+
+// runtime variables are passed between RoadRunner and its workers, they are not a part of the configuration.
+var runtime = map[string]bool{ //nolint:gochecknoglobals
+	"RR_RPC":              true,
+	"RR_RELAY":            true,
+	"RR_MODE":             true,
+	"RR_CODEC":            true,
+	"RR_PLUGIN_ADDRESS":   true,
+	"RR_UPGRADE_READY_FD": true,
+}
+
+// Variable is the environment variable mapped to the configuration key.
+type Variable struct {
+	// Name with the placeholders: <NAME> for the map entry name, <KEY> for the arbitrary key, <N> for the list index.
+	Name string
+	// Key is the configuration key, "*" stands for the map entry name or key, "#" for the list index.
+	Key string
+	// List value, comma-separated.
+	List bool
+}
+
+// node is the configuration tree built from the keys.
+type node struct {
+	children map[string]*node
+	// user-named map entry
+	named *node
+	// list item
+	index *node
+	// arbitrary string values
+	anyKey bool
+	leaf   bool
+	list   bool
+}
+
+// segment is the resolved key element.
+type segment struct {
+	name  string
+	index bool
+}
+
+// match is the resolved variable.
+type match struct {
+	segments []segment
+	list     bool
+	// the value is a string as is
+	raw bool
+}
+
+// indexed is the list under construction.
+type indexed map[int]interface{}
+
+// Variables returns the variables for the all available configuration keys.
+func Variables() []Variable {
+	vars := make([]Variable, 0, len(keys))
+
+	for _, key := range keys {
+		k := strings.TrimSuffix(key, "[]")
+		parts := strings.Split(k, ".")
+
+		for i, p := range parts {
+			switch {
+			case p == "*" && i == len(parts)-1:
+				parts[i] = keyPlaceholder
+			case p == "*":
+				parts[i] = namePlaceholder
+			case p == "#":
+				parts[i] = indexPlaceholder
+			default:
+				parts[i] = strings.ToUpper(p)
+			}
+		}
+
+		vars = append(vars, Variable{Name: Prefix + strings.Join(parts, "_"), Key: k, List: k != key})
+	}
+
+	return vars
+}
+
+// Runtime reports whether the variable is passed between RoadRunner and its workers and is not a configuration one.
+func Runtime(name string) bool {
+	return runtime[name]
+}
+
+// Resolve returns the configuration key of the variable, false when the variable is not mapped.
+func Resolve(name string) (string, bool) {
+	m, ok := tree().resolve(name)
+	if !ok {
+		return "", false
+	}
+
+	parts := make([]string, len(m.segments))
+	for i, s := range m.segments {
+		parts[i] = s.name
+	}
+
+	return strings.Join(parts, "."), true
+}
+
+// Build builds the configuration from the environment (KEY=value pairs). Unknown are the RR_ prefixed variables not
+// mapped to the configuration keys. Empty configuration is returned when there are no configuration variables.
+func Build(environ []string) (map[string]interface{}, []string, error) {
+	root := tree()
+	values := make(map[string]interface{})
+
+	var unknown []string
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, Prefix) || Runtime(name) {
+			continue
+		}
+
+		m, ok := root.resolve(name)
+		if !ok {
+			unknown = append(unknown, name)
+
+			continue
+		}
+
+		v, err := m.value(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("variable `%s`: %w", name, err)
+		}
+
+		if err = set(values, m.segments, v); err != nil {
+			return nil, nil, fmt.Errorf("variable `%s`: %w", name, err)
+		}
+	}
+
+	sort.Strings(unknown)
+
+	if len(values) == 0 {
+		return values, unknown, nil
+	}
+
+	if _, ok := values["version"]; !ok {
+		values["version"] = ConfigVersion
+	}
+
+	out, _ := finalize(values).(map[string]interface{})
+
+	return out, unknown, nil
+}
+
+// WriteTemp writes the configuration into the temporary YAML file, the caller removes it.
+func WriteTemp(values map[string]interface{}) (string, error) {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", "rr-env-*.yaml")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = f.Write(data)
+	if errC := f.Close(); err == nil {
+		err = errC
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+func tree() *node {
+	root := &node{}
+
+	for _, key := range keys {
+		n := root
+		k := strings.TrimSuffix(key, "[]")
+		parts := strings.Split(k, ".")
+
+		for i, p := range parts {
+			switch {
+			case p == "*" && i == len(parts)-1:
+				n.anyKey = true
+			case p == "*":
+				if n.named == nil {
+					n.named = &node{}
+				}
+
+				n = n.named
+			case p == "#":
+				if n.index == nil {
+					n.index = &node{}
+				}
+
+				n = n.index
+			default:
+				if n.children == nil {
+					n.children = make(map[string]*node)
+				}
+
+				if n.children[p] == nil {
+					n.children[p] = &node{}
+				}
+
+				n = n.children[p]
+			}
+		}
+
+		if parts[len(parts)-1] != "*" {
+			n.leaf, n.list = true, k != key
+		}
+	}
+
+	return root
+}
+
+// resolve maps the variable name to the configuration key.
+func (n *node) resolve(name string) (*match, bool) {
+	if !strings.HasPrefix(name, Prefix) {
+		return nil, false
+	}
+
+	tokens := strings.Split(strings.ToLower(strings.TrimPrefix(name, Prefix)), "_")
+	for _, t := range tokens {
+		if t == "" {
+			return nil, false
+		}
+	}
+
+	return n.match(tokens)
+}
+
+// match consumes the name tokens: the longest keys are tried first, the user-named entries take the shortest
+// names first.
+func (n *node) match(tokens []string) (*match, bool) {
+	if len(tokens) == 0 {
+		return &match{list: n.list}, n.leaf
+	}
+
+	candidates := make([]string, 0, len(n.children))
+	for k := range n.children {
+		candidates = append(candidates, k)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		li, lj := strings.Count(candidates[i], "_"), strings.Count(candidates[j], "_")
+		if li != lj {
+			return li > lj
+		}
+
+		return candidates[i] < candidates[j]
+	})
+
+	for _, k := range candidates {
+		kt := strings.Split(k, "_")
+		if len(kt) > len(tokens) || strings.Join(tokens[:len(kt)], "_") != k {
+			continue
+		}
+
+		if m, ok := n.children[k].match(tokens[len(kt):]); ok {
+			return m.prepend(segment{name: k}), true
+		}
+	}
+
+	if n.index != nil {
+		if _, err := strconv.Atoi(tokens[0]); err == nil {
+			if m, ok := n.index.match(tokens[1:]); ok {
+				return m.prepend(segment{name: tokens[0], index: true}), true
+			}
+		}
+	}
+
+	if n.named != nil {
+		for i := 1; i < len(tokens); i++ {
+			if m, ok := n.named.match(tokens[i:]); ok {
+				return m.prepend(segment{name: strings.Join(tokens[:i], "_")}), true
+			}
+		}
+	}
+
+	if n.anyKey {
+		return &match{segments: []segment{{name: strings.Join(tokens, "_")}}, raw: true}, true
+	}
+
+	return nil, false
+}
+
+func (m *match) prepend(s segment) *match {
+	m.segments = append([]segment{s}, m.segments...)
+
+	return m
+}
+
+// value parses the variable value.
+func (m *match) value(value string) (interface{}, error) {
+	if m.raw || (len(m.segments) == 1 && m.segments[0].name == "version") {
+		return value, nil
+	}
+
+	if !m.list {
+		return scalar(value), nil
+	}
+
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") {
+		var list []interface{}
+		if err := yaml.Unmarshal([]byte(value), &list); err != nil {
+			return nil, err
+		}
+
+		return list, nil
+	}
+
+	list := make([]interface{}, 0)
+	if value == "" {
+		return list, nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		list = append(list, scalar(strings.TrimSpace(item)))
+	}
+
+	return list, nil
+}
+
+// scalar parses the numbers and booleans, other values are strings.
+func scalar(value string) interface{} {
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &n); err != nil || len(n.Content) == 0 {
+		return value
+	}
+
+	s := n.Content[0]
+	if s.Kind != yaml.ScalarNode || (s.Tag != "!!int" && s.Tag != "!!float" && s.Tag != "!!bool") {
+		return value
+	}
+
+	var out interface{}
+	if err := s.Decode(&out); err != nil {
+		return value
+	}
+
+	return out
+}
+
+func set(values map[string]interface{}, segments []segment, value interface{}) error {
+	var cur interface{} = values
+
+	for i, s := range segments {
+		last := i == len(segments)-1
+
+		var next interface{}
+
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			if s.index {
+				return fmt.Errorf("`%s` is not a list", s.name)
+			}
+
+			if last {
+				c[s.name] = value
+
+				return nil
+			}
+
+			if c[s.name] == nil {
+				c[s.name] = container(segments[i+1])
+			}
+
+			next = c[s.name]
+		case indexed:
+			idx, _ := strconv.Atoi(s.name)
+			if last {
+				c[idx] = value
+
+				return nil
+			}
+
+			if c[idx] == nil {
+				c[idx] = container(segments[i+1])
+			}
+
+			next = c[idx]
+		default:
+			return fmt.Errorf("`%s` is already set to a value", s.name)
+		}
+
+		cur = next
+	}
+
+	return nil
+}
+
+func container(next segment) interface{} {
+	if next.index {
+		return indexed{}
+	}
+
+	return map[string]interface{}{}
+}
+
+// finalize turns the indexed lists into slices in the order of indexes.
+func finalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = finalize(item)
+		}
+
+		return v
+	case indexed:
+		idx := make([]int, 0, len(v))
+		for i := range v {
+			idx = append(idx, i)
+		}
+
+		sort.Ints(idx)
+
+		out := make([]interface{}, 0, len(v))
+		for _, i := range idx {
+			out = append(out, finalize(v[i]))
+		}
+
+		return out
+	default:
+		return value
+	}
+}
@@ -0,0 +1,113 @@
+package envconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolve_AllKeys(t *testing.T) {
+	for _, v := range Variables() {
+		name := strings.NewReplacer(namePlaceholder, "MY_APP", keyPlaceholder, "SOME_KEY", indexPlaceholder, "1").
+			Replace(v.Name)
+		want := strings.NewReplacer(".*.", ".my_app.", ".#.", ".1.").Replace(v.Key)
+		if strings.HasSuffix(want, ".*") {
+			want = strings.TrimSuffix(want, "*") + "some_key"
+		}
+
+		got, ok := Resolve(name)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, want, got, name)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	for name, want := range map[string]string{
+		"RR_HTTP_ADDRESS":                     "http.address",
+		"RR_HTTP_POOL_NUM_WORKERS":            "http.pool.num_workers",
+		"RR_LOGS_CHANNELS_HTTP_LEVEL":         "logs.channels.http.level",
+		"RR_KV_LOCAL_CACHE_CONFIG_INTERVAL":   "kv.local_cache.config.interval",
+		"RR_JOBS_PIPELINES_EMAILS_DRIVER":     "jobs.pipelines.emails.driver",
+		"RR_SERVER_ENV_APP_ENV":               "server.env.app_env",
+		"RR_EXTERNAL_PLUGINS_0_ENV_AUDIT_DSN": "external.plugins.0.env.audit_dsn",
+	} {
+		got, ok := Resolve(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	for _, name := range []string{"RR_HTTP", "RR_HTTP_UNKNOWN", "RR_HTTP__ADDRESS", "HTTP_ADDRESS", "RR_KV_DRIVER"} {
+		_, ok := Resolve(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	values, unknown, err := Build([]string{
+		"PATH=/bin",
+		"RR_RPC=tcp://127.0.0.1:6001",
+		"RR_RPC_LISTEN=tcp://127.0.0.1:6001",
+		"RR_SERVER_COMMAND=php worker.php",
+		"RR_SERVER_ENV_APP_DEBUG=true",
+		"RR_HTTP_ADDRESS=0.0.0.0:8080",
+		"RR_HTTP_MIDDLEWARE=gzip, headers",
+		"RR_HTTP_POOL_NUM_WORKERS=4",
+		"RR_HTTP_POOL_DEBUG=false",
+		"RR_RELOAD_PATTERNS=[.php, .go]",
+		"RR_KV_LOCAL_DRIVER=memory",
+		"RR_EXTERNAL_PLUGINS_1_NAME=auth",
+		"RR_EXTERNAL_PLUGINS_0_NAME=audit",
+		"RR_EXTERNAL_PLUGINS_0_ENV_AUDIT_DSN=dsn",
+		"RR_HTTP_ADRESS=typo",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"RR_HTTP_ADRESS"}, unknown)
+
+	assert.Equal(t, map[string]interface{}{
+		"version": ConfigVersion,
+		"rpc":     map[string]interface{}{"listen": "tcp://127.0.0.1:6001"},
+		"server": map[string]interface{}{
+			"command": "php worker.php",
+			// values of the environment are not parsed
+			"env": map[string]interface{}{"app_debug": "true"},
+		},
+		"http": map[string]interface{}{
+			"address":    "0.0.0.0:8080",
+			"middleware": []interface{}{"gzip", "headers"},
+			"pool":       map[string]interface{}{"num_workers": 4, "debug": false},
+		},
+		"reload": map[string]interface{}{"patterns": []interface{}{".php", ".go"}},
+		"kv":     map[string]interface{}{"local": map[string]interface{}{"driver": "memory"}},
+		"external": map[string]interface{}{"plugins": []interface{}{
+			map[string]interface{}{"name": "audit", "env": map[string]interface{}{"audit_dsn": "dsn"}},
+			map[string]interface{}{"name": "auth"},
+		}},
+	}, values)
+
+	values, unknown, err = Build([]string{"PATH=/bin", "RR_MODE=http"})
+	require.NoError(t, err)
+	assert.Empty(t, values)
+	assert.Empty(t, unknown)
+
+	_, _, err = Build([]string{"RR_HTTP_MIDDLEWARE=[gzip"})
+	assert.Error(t, err)
+}
+
+func TestWriteTemp(t *testing.T) {
+	path, err := WriteTemp(map[string]interface{}{"version": "2.7", "http": map[string]interface{}{"address": ":8080"}})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.Remove(path) })
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var out map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(data, &out))
+	assert.Equal(t, "2.7", out["version"])
+}
@@ -0,0 +1,165 @@
+// Command gen generates the configuration keys of the envconfig package from the reference configuration (.rr.yaml).
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const header = `// Code generated by internal/envconfig/gen from the reference configuration. DO NOT EDIT.
+
+package envconfig
+`
+
+// namedMaps are the maps with user-named entries (kv storages, jobs pipelines, etc.), their children are merged
+// under the "*" key.
+var namedMaps = map[string]bool{ //nolint:gochecknoglobals
+	"logs.channels":   true,
+	"kv":              true,
+	"service":         true,
+	"broadcast":       true,
+	"jobs.pipelines":  true,
+	"tcp.servers":     true,
+	"reload.services": true,
+	"metrics.collect": true,
+}
+
+// leafMaps are the maps with arbitrary string values (environment variables, headers), emitted as "path.*".
+var leafMaps = map[string]bool{ //nolint:gochecknoglobals
+	"server.env":                         true,
+	"server.on_init.env":                 true,
+	"service.*.env":                      true,
+	"grpc.env":                           true,
+	"external.plugins.#.env":             true,
+	"jobs.pipelines.*.config.attributes": true,
+	"jobs.pipelines.*.config.tags":       true,
+	"http.headers.request":               true,
+	"http.headers.response":              true,
+	"http.static.request":                true,
+	"http.static.response":               true,
+}
+
+// skipped keys can't be expressed with the environment variables.
+var skipped = map[string]bool{ //nolint:gochecknoglobals
+	"metrics.collect.*.objectives": true,
+}
+
+func main() {
+	config := flag.String("config", ".rr.yaml", "reference configuration")
+	out := flag.String("out", "keys.go", "generated file")
+	flag.Parse()
+
+	keys, err := Load(*config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	src, err := Generate(keys)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err = os.WriteFile(*out, src, 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Load reads the keys of the reference configuration: "*" stands for a user-named map entry, "#" for a list index,
+// "[]" suffix marks a list value.
+func Load(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("configuration `%s` is not a map", path)
+	}
+
+	set := make(map[string]struct{})
+	walk(doc.Content[0], "", set)
+
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func walk(node *yaml.Node, path string, set map[string]struct{}) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if namedMaps[path] {
+			key = "*"
+		}
+
+		p := key
+		if path != "" {
+			p = path + "." + key
+		}
+
+		value := node.Content[i+1]
+
+		switch {
+		case skipped[p]:
+		case leafMaps[p]:
+			set[p+".*"] = struct{}{}
+		case value.Kind == yaml.MappingNode && len(value.Content) == 0:
+			set[p+".*"] = struct{}{}
+		case value.Kind == yaml.MappingNode:
+			walk(value, p, set)
+		case value.Kind == yaml.SequenceNode && len(value.Content) > 0 && value.Content[0].Kind == yaml.MappingNode:
+			for _, item := range value.Content {
+				walk(item, p+".#", set)
+			}
+		case value.Kind == yaml.SequenceNode:
+			set[p+"[]"] = struct{}{}
+		default:
+			set[p] = struct{}{}
+		}
+	}
+}
+
+// Generate renders the envconfig package file with the keys.
+func Generate(keys []string) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys")
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(header)
+	buf.WriteString("\n// keys are the configuration keys available via the environment variables, edit the reference\n")
+	buf.WriteString("// configuration (.rr.yaml) and run `go generate` to update them.\n")
+	buf.WriteString("var keys = []string{ //nolint:gochecknoglobals\n")
+
+	for _, k := range keys {
+		if strings.ContainsAny(k, " \"") {
+			return nil, fmt.Errorf("invalid key `%s`", k)
+		}
+
+		fmt.Fprintf(&buf, "%q,\n", k)
+	}
+
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_InSync(t *testing.T) {
+	keys, err := Load("../../../.rr.yaml")
+	require.NoError(t, err)
+
+	src, err := Generate(keys)
+	require.NoError(t, err)
+
+	committed, err := os.ReadFile("../keys.go")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(committed), string(src), "keys.go is outdated, run `go generate ./internal/envconfig`")
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+version: "2.7"
+server:
+  command: php worker.php
+  env:
+    - APP_ENV: prod
+kv:
+  local:
+    driver: memory
+    config: {}
+  redis:
+    driver: redis
+    config:
+      addrs: [ "localhost:6379" ]
+external:
+  plugins:
+    - name: audit
+    - address: tcp://127.0.0.1:7001
+metrics:
+  collect:
+    app_metric:
+      objectives:
+        - 1.4: 2.3
+`), 0o600))
+
+	keys, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"external.plugins.#.address",
+		"external.plugins.#.name",
+		"kv.*.config.*",
+		"kv.*.config.addrs[]",
+		"kv.*.driver",
+		"server.command",
+		"server.env.*",
+		"version",
+	}, keys)
+
+	_, err = Generate(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,334 @@
+// Code generated by internal/envconfig/gen from the reference configuration. DO NOT EDIT.
+
+package envconfig
+
+// keys are the configuration keys available via the environment variables, edit the reference
+// configuration (.rr.yaml) and run `go generate` to update them.
+var keys = []string{ //nolint:gochecknoglobals
+	"amqp.addr",
+	"beanstalk.addr",
+	"beanstalk.timeout",
+	"boltdb.permissions",
+	"broadcast.*.config.*",
+	"broadcast.*.config.addrs[]",
+	"broadcast.*.config.db",
+	"broadcast.*.config.dial_timeout",
+	"broadcast.*.config.idle_check_freq",
+	"broadcast.*.config.idle_timeout",
+	"broadcast.*.config.master_name",
+	"broadcast.*.config.max_conn_age",
+	"broadcast.*.config.max_retries",
+	"broadcast.*.config.max_retry_backoff",
+	"broadcast.*.config.min_idle_conns",
+	"broadcast.*.config.min_retry_backoff",
+	"broadcast.*.config.password",
+	"broadcast.*.config.pool_size",
+	"broadcast.*.config.pool_timeout",
+	"broadcast.*.config.read_only",
+	"broadcast.*.config.read_timeout",
+	"broadcast.*.config.route_by_latency",
+	"broadcast.*.config.route_randomly",
+	"broadcast.*.config.sentinel_password",
+	"broadcast.*.config.username",
+	"broadcast.*.config.write_timeout",
+	"broadcast.*.driver",
+	"debug.address",
+	"debug.basic_auth.password",
+	"debug.basic_auth.username",
+	"debug.block_profile_rate",
+	"debug.mutex_profile_fraction",
+	"debug.profiling.cpu_duration",
+	"debug.profiling.dir",
+	"debug.profiling.interval",
+	"debug.profiling.max_files",
+	"debug.profiling.max_size",
+	"debug.profiling.profiles[]",
+	"debug.trace_duration",
+	"debug.watchdog.cooldown",
+	"debug.watchdog.dir",
+	"debug.watchdog.interval",
+	"debug.watchdog.max_files",
+	"debug.watchdog.max_goroutines",
+	"debug.watchdog.max_heap",
+	"debug.watchdog.max_rss",
+	"debug.watchdog.max_size",
+	"endure.disabled_plugins[]",
+	"endure.grace_period",
+	"endure.log_level",
+	"endure.print_graph",
+	"external.call_timeout",
+	"external.health_check_interval",
+	"external.max_restarts",
+	"external.plugins.#.address",
+	"external.plugins.#.command",
+	"external.plugins.#.env.*",
+	"external.plugins.#.name",
+	"external.restart_backoff",
+	"external.timeout",
+	"fileserver.address",
+	"fileserver.calculate_etag",
+	"fileserver.serve.#.bytes_range",
+	"fileserver.serve.#.cache_duration",
+	"fileserver.serve.#.compress",
+	"fileserver.serve.#.max_age",
+	"fileserver.serve.#.prefix",
+	"fileserver.serve.#.root",
+	"fileserver.stream_request_body",
+	"fileserver.weak",
+	"grpc.listen",
+	"grpc.max_concurrent_streams",
+	"grpc.max_connection_age",
+	"grpc.max_connection_age_grace",
+	"grpc.max_connection_idle",
+	"grpc.max_recv_msg_size",
+	"grpc.max_send_msg_size",
+	"grpc.ping_time",
+	"grpc.pool.allocate_timeout",
+	"grpc.pool.destroy_timeout",
+	"grpc.pool.max_jobs",
+	"grpc.pool.num_workers",
+	"grpc.proto[]",
+	"grpc.timeout",
+	"grpc.tls.cert",
+	"grpc.tls.client_auth_type",
+	"grpc.tls.key",
+	"grpc.tls.root_ca",
+	"har.dir",
+	"har.flush_interval",
+	"har.max_body_size",
+	"har.max_entries",
+	"har.max_files",
+	"har.max_size",
+	"har.redact_headers[]",
+	"har.redact_query[]",
+	"har.sample_rate",
+	"http.access_logs",
+	"http.address",
+	"http.cache.cache_methods[]",
+	"http.cache.config.*",
+	"http.cache.driver",
+	"http.fcgi.address",
+	"http.headers.cors.allow_credentials",
+	"http.headers.cors.allowed_headers",
+	"http.headers.cors.allowed_methods",
+	"http.headers.cors.allowed_origin",
+	"http.headers.cors.exposed_headers",
+	"http.headers.cors.max_age",
+	"http.headers.request.*",
+	"http.headers.response.*",
+	"http.http2.h2c",
+	"http.http2.max_concurrent_streams",
+	"http.internal_error_code",
+	"http.max_request_size",
+	"http.middleware[]",
+	"http.new_relic.app_name",
+	"http.new_relic.license_key",
+	"http.otel.client",
+	"http.otel.compress",
+	"http.otel.custom_url",
+	"http.otel.endpoint",
+	"http.otel.exporter",
+	"http.otel.insecure",
+	"http.otel.service_name",
+	"http.otel.service_version",
+	"http.pool.allocate_timeout",
+	"http.pool.command",
+	"http.pool.debug",
+	"http.pool.destroy_timeout",
+	"http.pool.max_jobs",
+	"http.pool.num_workers",
+	"http.pool.supervisor.exec_ttl",
+	"http.pool.supervisor.idle_ttl",
+	"http.pool.supervisor.max_worker_memory",
+	"http.pool.supervisor.ttl",
+	"http.pool.supervisor.watch_tick",
+	"http.ssl.acme.alt_http_port",
+	"http.ssl.acme.alt_tlsalpn_port",
+	"http.ssl.acme.certs_dir",
+	"http.ssl.acme.challenge_type",
+	"http.ssl.acme.domains[]",
+	"http.ssl.acme.email",
+	"http.ssl.acme.use_production_endpoint",
+	"http.ssl.address",
+	"http.ssl.cert",
+	"http.ssl.client_auth_type",
+	"http.ssl.key",
+	"http.ssl.redirect",
+	"http.ssl.root_ca",
+	"http.static.allow[]",
+	"http.static.calculate_etag",
+	"http.static.dir",
+	"http.static.forbid[]",
+	"http.static.request.*",
+	"http.static.response.*",
+	"http.static.weak",
+	"http.trusted_subnets[]",
+	"http.uploads.allow[]",
+	"http.uploads.dir",
+	"http.uploads.forbid[]",
+	"jobs.consume[]",
+	"jobs.num_pollers",
+	"jobs.pipeline_size",
+	"jobs.pipelines.*.config.attributes.*",
+	"jobs.pipelines.*.config.consume_all",
+	"jobs.pipelines.*.config.delete_after_ack",
+	"jobs.pipelines.*.config.delete_queue_on_stop",
+	"jobs.pipelines.*.config.delete_stream_on_stop",
+	"jobs.pipelines.*.config.deliver_new",
+	"jobs.pipelines.*.config.durable",
+	"jobs.pipelines.*.config.exchange",
+	"jobs.pipelines.*.config.exchange_type",
+	"jobs.pipelines.*.config.exclusive",
+	"jobs.pipelines.*.config.file",
+	"jobs.pipelines.*.config.multiple_ack",
+	"jobs.pipelines.*.config.prefetch",
+	"jobs.pipelines.*.config.priority",
+	"jobs.pipelines.*.config.queue",
+	"jobs.pipelines.*.config.rate_limit",
+	"jobs.pipelines.*.config.requeue_on_fail",
+	"jobs.pipelines.*.config.reserve_timeout",
+	"jobs.pipelines.*.config.routing_key",
+	"jobs.pipelines.*.config.skip_queue_declaration",
+	"jobs.pipelines.*.config.stream",
+	"jobs.pipelines.*.config.subject",
+	"jobs.pipelines.*.config.tags.*",
+	"jobs.pipelines.*.config.tube",
+	"jobs.pipelines.*.config.tube_priority",
+	"jobs.pipelines.*.config.visibility_timeout",
+	"jobs.pipelines.*.config.wait_time_seconds",
+	"jobs.pipelines.*.driver",
+	"jobs.pool.allocate_timeout",
+	"jobs.pool.command",
+	"jobs.pool.destroy_timeout",
+	"jobs.pool.max_jobs",
+	"jobs.pool.num_workers",
+	"kv.*.config.addr[]",
+	"kv.*.config.addrs[]",
+	"kv.*.config.db",
+	"kv.*.config.dial_timeout",
+	"kv.*.config.file",
+	"kv.*.config.idle_check_freq",
+	"kv.*.config.idle_timeout",
+	"kv.*.config.interval",
+	"kv.*.config.master_name",
+	"kv.*.config.max_conn_age",
+	"kv.*.config.max_retries",
+	"kv.*.config.max_retry_backoff",
+	"kv.*.config.min_idle_conns",
+	"kv.*.config.min_retry_backoff",
+	"kv.*.config.password",
+	"kv.*.config.permissions",
+	"kv.*.config.pool_size",
+	"kv.*.config.pool_timeout",
+	"kv.*.config.read_only",
+	"kv.*.config.read_timeout",
+	"kv.*.config.route_by_latency",
+	"kv.*.config.route_randomly",
+	"kv.*.config.sentinel_password",
+	"kv.*.config.username",
+	"kv.*.config.write_timeout",
+	"kv.*.driver",
+	"logs.channels.*.encoding",
+	"logs.channels.*.err_output",
+	"logs.channels.*.level",
+	"logs.channels.*.mode",
+	"logs.channels.*.output",
+	"logs.encoding",
+	"logs.err_output",
+	"logs.file_logger_options.compress",
+	"logs.file_logger_options.log_output",
+	"logs.file_logger_options.max_age",
+	"logs.file_logger_options.max_backups",
+	"logs.file_logger_options.max_size",
+	"logs.level",
+	"logs.line_ending",
+	"logs.mode",
+	"logs.output",
+	"metrics.address",
+	"metrics.collect.*.buckets[]",
+	"metrics.collect.*.help",
+	"metrics.collect.*.labels[]",
+	"metrics.collect.*.type",
+	"nats.addr",
+	"redis.addrs[]",
+	"redis.db",
+	"redis.dial_timeout",
+	"redis.idle_check_freq",
+	"redis.idle_timeout",
+	"redis.master_name",
+	"redis.max_conn_age",
+	"redis.max_retries",
+	"redis.max_retry_backoff",
+	"redis.min_idle_conns",
+	"redis.min_retry_backoff",
+	"redis.password",
+	"redis.pool_size",
+	"redis.pool_timeout",
+	"redis.read_only",
+	"redis.read_timeout",
+	"redis.route_by_latency",
+	"redis.route_randomly",
+	"redis.sentinel_password",
+	"redis.username",
+	"redis.write_timeout",
+	"reload.interval",
+	"reload.patterns[]",
+	"reload.services.*.dirs[]",
+	"reload.services.*.ignore[]",
+	"reload.services.*.patterns[]",
+	"reload.services.*.recursive",
+	"rpc.listen",
+	"server.command",
+	"server.env.*",
+	"server.group",
+	"server.on_init.command",
+	"server.on_init.env.*",
+	"server.on_init.exec_timeout",
+	"server.relay",
+	"server.relay_timeout",
+	"server.user",
+	"service.*.command",
+	"service.*.env.*",
+	"service.*.exec_timeout",
+	"service.*.process_num",
+	"service.*.remain_after_exit",
+	"service.*.restart_sec",
+	"sqs.endpoint",
+	"sqs.key",
+	"sqs.region",
+	"sqs.secret",
+	"sqs.session_token",
+	"status.address",
+	"status.unavailable_status_code",
+	"tcp.pool.allocate_timeout",
+	"tcp.pool.command",
+	"tcp.pool.destroy_timeout",
+	"tcp.pool.max_jobs",
+	"tcp.pool.num_workers",
+	"tcp.servers.*.addr",
+	"tcp.servers.*.delimiter",
+	"tcp.servers.*.read_buf_size",
+	"temporal.activities.allocate_timeout",
+	"temporal.activities.command",
+	"temporal.activities.debug",
+	"temporal.activities.destroy_timeout",
+	"temporal.activities.max_jobs",
+	"temporal.activities.num_workers",
+	"temporal.activities.supervisor.exec_ttl",
+	"temporal.activities.supervisor.idle_ttl",
+	"temporal.activities.supervisor.max_worker_memory",
+	"temporal.activities.supervisor.ttl",
+	"temporal.activities.supervisor.watch_tick",
+	"temporal.address",
+	"temporal.cache_size",
+	"temporal.codec",
+	"temporal.debug_level",
+	"temporal.metrics.address",
+	"temporal.metrics.prefix",
+	"temporal.metrics.type",
+	"temporal.namespace",
+	"version",
+	"websockets.allowed_origin",
+	"websockets.broker",
+	"websockets.path",
+}
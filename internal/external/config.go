@@ -0,0 +1,157 @@
+package external
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/roadrunner-server/errors"
+)
+
+// Config of the external plugins.
+type Config struct {
+	// Timeout of the handshake, health check and middleware calls.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// CallTimeout of the plugin RPC methods called via `external.Call`.
+	CallTimeout time.Duration `mapstructure:"call_timeout"`
+	// HealthCheckInterval between the health checks of every plugin, 0 disables health checks.
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
+	// MaxRestarts of the crashed or unhealthy plugin in a row, RoadRunner fails after that.
+	MaxRestarts int `mapstructure:"max_restarts"`
+	// RestartBackoff is the initial restart delay, doubled with every restart in a row (up to a minute).
+	RestartBackoff time.Duration `mapstructure:"restart_backoff"`
+	// Plugins in the HTTP middleware order.
+	Plugins []*PluginConfig `mapstructure:"plugins"`
+}
+
+// PluginConfig declares a single external plugin: either a command to launch or an address of the running plugin.
+type PluginConfig struct {
+	Name string `mapstructure:"name"`
+	// Command to launch (binary and arguments split as by the shell: quotes and backslash escapes are supported, but no
+	// expansions), the plugin listens on the address from the RR_PLUGIN_ADDRESS variable.
+	Command string `mapstructure:"command"`
+	// Env of the launched plugin.
+	Env map[string]string `mapstructure:"env"`
+	// Address of the plugin started by someone else (tcp://127.0.0.1:7001, unix:///tmp/plugin.sock).
+	Address string `mapstructure:"address"`
+}
+
+// InitDefaults sets default values.
+func (c *Config) InitDefaults() {
+	if c.Timeout == 0 {
+		c.Timeout = time.Second * 5
+	}
+
+	if c.CallTimeout == 0 {
+		c.CallTimeout = time.Minute
+	}
+
+	if c.HealthCheckInterval == 0 {
+		c.HealthCheckInterval = time.Second * 10
+	}
+
+	if c.MaxRestarts == 0 {
+		c.MaxRestarts = 5
+	}
+
+	if c.RestartBackoff == 0 {
+		c.RestartBackoff = time.Second
+	}
+}
+
+// Valid validates the configuration.
+func (c *Config) Valid() error {
+	names := make(map[string]struct{}, len(c.Plugins))
+
+	for i, p := range c.Plugins {
+		if p == nil || p.Name == "" {
+			return fmt.Errorf("external plugin #%d has no name", i)
+		}
+
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicate external plugin `%s`", p.Name)
+		}
+
+		names[p.Name] = struct{}{}
+
+		if (p.Command == "") == (p.Address == "") {
+			return fmt.Errorf("external plugin `%s`: either command or address should be set", p.Name)
+		}
+
+		if p.Command != "" {
+			if _, err := splitCommand(p.Command); err != nil {
+				return fmt.Errorf("external plugin `%s`: %w", p.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// splitCommand splits the command into the binary and arguments as the POSIX shell does: single quotes preserve the
+// text as is, backslash escapes the next character outside single quotes. Variables, globs and other expansions are
+// not supported.
+func splitCommand(cmd string) ([]string, error) {
+	var (
+		args  []string
+		arg   strings.Builder
+		inArg bool
+		quote rune
+		esc   bool
+	)
+
+	for _, r := range cmd {
+		switch {
+		case esc:
+			esc = false
+			// inside double quotes backslash escapes only the special characters
+			if quote == '"' && !strings.ContainsRune(`"\$`+"`", r) {
+				arg.WriteRune('\\')
+			}
+
+			arg.WriteRune(r)
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			esc, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	switch {
+	case esc:
+		return nil, errors.Str("command ends with a backslash")
+	case quote != 0:
+		return nil, fmt.Errorf("command has an unterminated %c quote", quote)
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	if len(args) == 0 {
+		return nil, errors.Str("command is empty")
+	}
+
+	return args, nil
+}
@@ -0,0 +1,183 @@
+// Package external runs out-of-process plugins (see pkg/external for the protocol): RPC methods are available via
+// `external.Call`, HTTP middleware via the `external` http middleware, plugins health via the status plugin.
+package external
+
+import (
+	"net/http"
+
+	protocol "github.com/roadrunner-server/roadrunner/v2/pkg/external"
+
+	"github.com/roadrunner-server/api/v2/plugins/config"
+	"github.com/roadrunner-server/api/v2/plugins/status"
+	"github.com/roadrunner-server/errors"
+	"go.uber.org/zap"
+)
+
+// PluginName is the name of the plugin and its configuration section.
+const PluginName string = "external"
+
+// Plugin manages external plugins.
+type Plugin struct {
+	cfg   *Config
+	log   *zap.Logger
+	procs []*process
+	// plugins registered in the container, the manifest dependencies are resolved against them
+	registered map[string]struct{}
+}
+
+// SetPlugins sets the names of the plugins registered in the container (called before the container initialization).
+func (p *Plugin) SetPlugins(names []string) {
+	p.registered = make(map[string]struct{}, len(names))
+	for _, n := range names {
+		p.registered[n] = struct{}{}
+	}
+}
+
+// Init reads the configuration, plugins are started in Serve.
+func (p *Plugin) Init(cfg config.Configurer, log *zap.Logger) error {
+	const op = errors.Op("external_plugin_init")
+
+	if !cfg.Has(PluginName) {
+		return errors.E(op, errors.Disabled)
+	}
+
+	err := cfg.UnmarshalKey(PluginName, &p.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	p.cfg.InitDefaults()
+
+	if err = p.cfg.Valid(); err != nil {
+		return errors.E(op, err)
+	}
+
+	p.log = log
+	p.procs = make([]*process, 0, len(p.cfg.Plugins))
+
+	// the dependency should be registered and configured: plugins without the configuration section are disabled
+	depends := func(name string) error {
+		if _, ok := p.registered[name]; !ok {
+			return errors.Str("which is not registered in the container")
+		}
+
+		if !cfg.Has(name) {
+			return errors.Str("which is not configured")
+		}
+
+		return nil
+	}
+
+	for _, pc := range p.cfg.Plugins {
+		p.procs = append(p.procs, newProcess(pc, p.cfg, log, cfg.RRVersion(), depends))
+	}
+
+	return nil
+}
+
+// Serve starts all plugins. Plugins failed to restart are reported via the errors channel.
+func (p *Plugin) Serve() chan error {
+	const op = errors.Op("external_plugin_serve")
+
+	errCh := make(chan error, len(p.procs))
+
+	for _, proc := range p.procs {
+		if err := proc.start(); err != nil {
+			errCh <- errors.E(op, err)
+
+			return errCh
+		}
+
+		proc.run(errCh)
+	}
+
+	return errCh
+}
+
+// Stop stops all plugins.
+func (p *Plugin) Stop() error {
+	for _, proc := range p.procs {
+		proc.close()
+	}
+
+	return nil
+}
+
+// Name returns the plugin name.
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// RPC returns associated rpc service.
+func (p *Plugin) RPC() interface{} {
+	return &rpc{plugin: p}
+}
+
+// Status is OK when all plugins are healthy.
+func (p *Plugin) Status() (*status.Status, error) {
+	for _, proc := range p.procs {
+		if _, healthy := proc.state(); !healthy {
+			return &status.Status{Code: http.StatusServiceUnavailable}, nil
+		}
+	}
+
+	return &status.Status{Code: http.StatusOK}, nil
+}
+
+// Middleware passes requests through the plugins declaring the middleware, in the configuration order. Requests are
+// rejected with 503 when a plugin does not respond.
+func (p *Plugin) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, proc := range p.procs {
+			if m, _ := proc.state(); !m.Middleware {
+				continue
+			}
+
+			var resp protocol.MiddlewareResponse
+
+			err := proc.call(protocol.MethodMiddleware, protocol.MiddlewareRequest{
+				Method:     r.Method,
+				URI:        r.RequestURI,
+				RemoteAddr: r.RemoteAddr,
+				Header:     r.Header,
+			}, &resp)
+			if err != nil {
+				proc.log.Error("external middleware failed", zap.Error(err))
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+
+				return
+			}
+
+			for k, vv := range resp.Header {
+				for _, v := range vv {
+					w.Header().Add(k, v)
+				}
+			}
+
+			if resp.Status != 0 {
+				w.WriteHeader(resp.Status)
+				_, _ = w.Write(resp.Body)
+
+				return
+			}
+
+			for k, vv := range resp.RequestHeader {
+				for _, v := range vv {
+					r.Header.Add(k, v)
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (p *Plugin) process(name string) *process {
+	for _, proc := range p.procs {
+		if proc.cfg.Name == name {
+			return proc
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	protocol "github.com/roadrunner-server/roadrunner/v2/pkg/external"
+
+	"github.com/roadrunner-server/config/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const envTestPlugin = "RR_EXTERNAL_TEST_PLUGIN"
+
+// fake is the external plugin allowing requests with the token.
+type fake struct{}
+
+func (f *fake) Manifest(*protocol.HandshakeRequest) protocol.Manifest {
+	return protocol.Manifest{RPC: []string{"Echo.Upper", "Echo.Sleep"}, Middleware: true, Depends: []string{"rpc"}}
+}
+
+func (f *fake) Health() error { return nil }
+
+func (f *fake) Middleware(req *protocol.MiddlewareRequest, resp *protocol.MiddlewareResponse) error {
+	if req.Header.Get("X-Token") != "secret" {
+		resp.Status = http.StatusForbidden
+		resp.Body = []byte("forbidden")
+
+		return nil
+	}
+
+	resp.Header = http.Header{"X-Checked": []string{"yes"}}
+	resp.RequestHeader = http.Header{"X-User": []string{"admin"}}
+
+	return nil
+}
+
+type echo struct{}
+
+func (e *echo) Upper(in []byte, out *[]byte) error {
+	var s string
+	if err := json.Unmarshal(in, &s); err != nil {
+		return err
+	}
+
+	*out = []byte(fmt.Sprintf("%q", s+"!"))
+
+	return nil
+}
+
+func (e *echo) Sleep(_ []byte, out *[]byte) error {
+	time.Sleep(time.Second)
+	*out = []byte("null")
+
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	// launched by the plugin under the test
+	if os.Getenv(envTestPlugin) != "" {
+		if err := protocol.Serve(&fake{}, map[string]interface{}{"Echo": &echo{}}); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+func newPlugin(t *testing.T, cfg string) *Plugin {
+	c := &config.Plugin{Type: "yaml", ReadInCfg: []byte(cfg)}
+	require.NoError(t, c.Init())
+
+	p := &Plugin{}
+	p.SetPlugins([]string{"config", "rpc", PluginName})
+	require.NoError(t, p.Init(c, zap.NewNop()))
+
+	return p
+}
+
+func testPlugin(t *testing.T, p *Plugin) {
+	var out json.RawMessage
+
+	err := p.RPC().(*rpc).Call(&protocol.CallRequest{Plugin: "fake", Method: "Echo.Upper", Payload: []byte(`"hi"`)}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, `"hi!"`, string(out))
+
+	err = p.RPC().(*rpc).Call(&protocol.CallRequest{Plugin: "fake", Method: "Echo.Lower"}, &out)
+	assert.Error(t, err)
+
+	h := p.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-User")))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "forbidden", rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Token", "secret")
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "admin", rec.Body.String())
+	assert.Equal(t, "yes", rec.Header().Get("X-Checked"))
+
+	st, err := p.Status()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, st.Code)
+}
+
+func TestPlugin_Address(t *testing.T) {
+	addr := "unix://" + filepath.Join(t.TempDir(), "fake.sock")
+
+	l, err := protocol.Listen(addr)
+	require.NoError(t, err)
+
+	defer func() { _ = l.Close() }()
+
+	go func() { _ = protocol.ServeListener(l, &fake{}, map[string]interface{}{"Echo": &echo{}}) }()
+
+	p := newPlugin(t, fmt.Sprintf(`
+rpc:
+  listen: tcp://127.0.0.1:6001
+external:
+  plugins:
+    - name: fake
+      address: %s
+`, addr))
+
+	errCh := p.Serve()
+
+	testPlugin(t, p)
+
+	require.NoError(t, p.Stop())
+	assert.Empty(t, errCh)
+}
+
+func TestPlugin_CallTimeout(t *testing.T) {
+	addr := "unix://" + filepath.Join(t.TempDir(), "fake.sock")
+
+	l, err := protocol.Listen(addr)
+	require.NoError(t, err)
+
+	defer func() { _ = l.Close() }()
+
+	go func() { _ = protocol.ServeListener(l, &fake{}, map[string]interface{}{"Echo": &echo{}}) }()
+
+	p := newPlugin(t, fmt.Sprintf(`
+rpc:
+  listen: tcp://127.0.0.1:6001
+external:
+  call_timeout: 100ms
+  plugins:
+    - name: fake
+      address: %s
+`, addr))
+
+	errCh := p.Serve()
+
+	var out json.RawMessage
+
+	err = p.RPC().(*rpc).Call(&protocol.CallRequest{Plugin: "fake", Method: "Echo.Sleep"}, &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+
+	require.NoError(t, p.Stop())
+	assert.Empty(t, errCh)
+}
+
+func TestPlugin_Command(t *testing.T) {
+	p := newPlugin(t, fmt.Sprintf(`
+rpc:
+  listen: tcp://127.0.0.1:6001
+external:
+  restart_backoff: 10ms
+  plugins:
+    - name: fake
+      command: %s -test.run='^$'
+      env:
+        %s: "1"
+`, os.Args[0], envTestPlugin))
+
+	errCh := p.Serve()
+
+	testPlugin(t, p)
+
+	// crashed plugin is restarted
+	proc := p.process("fake")
+	proc.mu.RLock()
+	pid := proc.cmd.Process.Pid
+	require.NoError(t, proc.cmd.Process.Kill())
+	proc.mu.RUnlock()
+
+	assert.Eventually(t, func() bool {
+		proc.mu.RLock()
+		defer proc.mu.RUnlock()
+
+		return proc.cmd != nil && proc.cmd.Process.Pid != pid && proc.healthy
+	}, time.Second*5, time.Millisecond*50)
+
+	testPlugin(t, p)
+
+	require.NoError(t, p.Stop())
+	assert.Empty(t, errCh)
+}
+
+func TestPlugin_Handshake(t *testing.T) {
+	addr := "unix://" + filepath.Join(t.TempDir(), "fake.sock")
+
+	l, err := protocol.Listen(addr)
+	require.NoError(t, err)
+
+	defer func() { _ = l.Close() }()
+
+	go func() { _ = protocol.ServeListener(l, &fake{}, nil) }()
+
+	// the plugin depends on the rpc plugin, which is not configured
+	p := newPlugin(t, fmt.Sprintf("external:\n  plugins:\n    - name: fake\n      address: %s\n", addr))
+
+	err = <-p.Serve()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "depends on `rpc`, which is not configured")
+	assert.NoError(t, p.Stop())
+
+	// configured, but not registered (e.g. excluded)
+	p = newPlugin(t, fmt.Sprintf("rpc:\n  listen: tcp://127.0.0.1:6001\nexternal:\n  plugins:\n    - name: fake\n"+
+		"      address: %s\n", addr))
+	p.SetPlugins([]string{"config", PluginName})
+
+	err = <-p.Serve()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "depends on `rpc`, which is not registered in the container")
+	assert.NoError(t, p.Stop())
+}
+
+func TestConfig_Valid(t *testing.T) {
+	for _, cfg := range []*Config{
+		{Plugins: []*PluginConfig{{Command: "plugin"}}},
+		{Plugins: []*PluginConfig{{Name: "a", Command: "a"}, {Name: "a", Command: "b"}}},
+		{Plugins: []*PluginConfig{{Name: "a"}}},
+		{Plugins: []*PluginConfig{{Name: "a", Command: "a", Address: "tcp://127.0.0.1:7001"}}},
+		{Plugins: []*PluginConfig{{Name: "a", Command: "a 'b"}}},
+		{Plugins: []*PluginConfig{{Name: "a", Command: "a b\\"}}},
+		{Plugins: []*PluginConfig{{Name: "a", Command: "  "}}},
+	} {
+		assert.Error(t, cfg.Valid())
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	for cmd, args := range map[string][]string{
+		"plugin":                        {"plugin"},
+		"  plugin  -v\t--x=1 ":          {"plugin", "-v", "--x=1"},
+		`plugin --format 'json lines'`:  {"plugin", "--format", "json lines"},
+		`plugin "a \"b\" \c" ''`:        {"plugin", `a "b" \c`, ""},
+		`/opt/my\ plugin/bin 'it'\''s'`: {"/opt/my plugin/bin", "it's"},
+		`plugin a"b c"d`:                {"plugin", "ab cd"},
+	} {
+		got, err := splitCommand(cmd)
+		require.NoError(t, err, cmd)
+		assert.Equal(t, args, got, cmd)
+	}
+}
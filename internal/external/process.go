@@ -0,0 +1,382 @@
+package external
+
+import (
+	"fmt"
+	netRpc "net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	protocol "github.com/roadrunner-server/roadrunner/v2/pkg/external"
+
+	"github.com/roadrunner-server/errors"
+	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
+	"go.uber.org/zap"
+)
+
+// maxRestartBackoff caps the exponential restart delay.
+const maxRestartBackoff = time.Minute
+
+// process is a single external plugin: launched binary or connection to the running one.
+type process struct {
+	cfg  *PluginConfig
+	opts *Config
+	log  *zap.Logger
+	// RR version and the dependencies resolver for the handshake
+	version string
+	depends func(name string) error
+
+	mu       sync.RWMutex
+	client   *netRpc.Client
+	manifest protocol.Manifest
+	healthy  bool
+	// launched process, exited is closed when it exits
+	cmd    *exec.Cmd
+	exited chan struct{}
+	dir    string
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProcess(cfg *PluginConfig, opts *Config, log *zap.Logger, version string, depends func(string) error) *process {
+	return &process{
+		cfg:     cfg,
+		opts:    opts,
+		log:     log.With(zap.String("plugin", cfg.Name)),
+		version: version,
+		depends: depends,
+		stop:    make(chan struct{}),
+	}
+}
+
+// start launches the plugin (when the command is set), connects to it and makes the handshake.
+func (p *process) start() error {
+	const op = errors.Op("external_plugin_start")
+
+	addr := p.cfg.Address
+	if p.cfg.Command != "" {
+		var err error
+		if addr, err = p.launch(); err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	client, err := p.connect(addr)
+	if err != nil {
+		p.shutdown()
+
+		return errors.E(op, err)
+	}
+
+	p.mu.Lock()
+	p.client = client
+	p.mu.Unlock()
+
+	var manifest protocol.Manifest
+
+	err = p.call(protocol.MethodHandshake, protocol.HandshakeRequest{
+		Protocol:  protocol.ProtocolVersion,
+		Name:      p.cfg.Name,
+		RRVersion: p.version,
+	}, &manifest)
+	if err != nil {
+		p.shutdown()
+
+		return errors.E(op, fmt.Errorf("handshake: %w", err))
+	}
+
+	if err = p.validate(&manifest); err != nil {
+		p.shutdown()
+
+		return errors.E(op, err)
+	}
+
+	p.mu.Lock()
+	p.manifest = manifest
+	p.healthy = true
+	p.mu.Unlock()
+
+	p.log.Info("external plugin started", zap.Strings("rpc", manifest.RPC), zap.Bool("middleware", manifest.Middleware))
+
+	return nil
+}
+
+func (p *process) validate(m *protocol.Manifest) error {
+	if m.Protocol != protocol.ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d, expected %d", m.Protocol, protocol.ProtocolVersion)
+	}
+
+	for _, dep := range m.Depends {
+		if err := p.depends(dep); err != nil {
+			return fmt.Errorf("plugin depends on `%s`, %w", dep, err)
+		}
+	}
+
+	return nil
+}
+
+// launch starts the plugin binary listening on the unix socket in a private directory.
+func (p *process) launch() (string, error) {
+	args, err := splitCommand(p.cfg.Command)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "rr-external-")
+	if err != nil {
+		return "", err
+	}
+
+	addr := "unix://" + filepath.Join(dir, "plugin.sock")
+
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), protocol.EnvAddress+"="+addr)
+
+	for k, v := range p.cfg.Env {
+		cmd.Env = append(cmd.Env, strings.ToUpper(k)+"="+os.ExpandEnv(v))
+	}
+
+	if err = cmd.Start(); err != nil {
+		_ = os.RemoveAll(dir)
+
+		return "", err
+	}
+
+	exited := make(chan struct{})
+
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	p.mu.Lock()
+	p.cmd, p.exited, p.dir = cmd, exited, dir
+	p.mu.Unlock()
+
+	return addr, nil
+}
+
+// connect dials the plugin until it starts listening or the timeout passes.
+func (p *process) connect(addr string) (*netRpc.Client, error) {
+	deadline := time.Now().Add(p.opts.Timeout)
+
+	for {
+		conn, err := protocol.Dial(addr)
+		if err == nil {
+			return netRpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn)), nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("plugin is not listening on %s: %w", addr, err)
+		}
+
+		select {
+		case <-p.exitedCh():
+			return nil, fmt.Errorf("plugin exited before listening on %s", addr)
+		case <-time.After(time.Millisecond * 50):
+		}
+	}
+}
+
+// shutdown closes the connection and stops the launched plugin.
+func (p *process) shutdown() {
+	p.mu.Lock()
+	client, cmd, exited, dir := p.client, p.cmd, p.exited, p.dir
+	p.client, p.cmd, p.exited, p.dir, p.healthy = nil, nil, nil, "", false
+	p.mu.Unlock()
+
+	if client != nil {
+		_ = client.Close()
+	}
+
+	if cmd != nil {
+		// interrupt is not supported on windows
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			_ = cmd.Process.Kill()
+		}
+
+		select {
+		case <-exited:
+		case <-time.After(p.opts.Timeout):
+			_ = cmd.Process.Kill()
+			<-exited
+		}
+	}
+
+	if dir != "" {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// run watches the plugin: restarts it when the launched process exits or the health check fails. Error is sent when
+// the plugin can't be restarted.
+func (p *process) run(errCh chan<- error) {
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		var tick <-chan time.Time
+		if p.opts.HealthCheckInterval > 0 {
+			t := time.NewTicker(p.opts.HealthCheckInterval)
+			defer t.Stop()
+
+			tick = t.C
+		}
+
+		// restarts in a row
+		restarts := 0
+
+		for {
+			var reason error
+
+			select {
+			case <-p.stop:
+				return
+			case <-p.exitedCh():
+				reason = errors.Str("plugin process exited")
+			case <-tick:
+				if reason = p.health(); reason == nil {
+					restarts = 0
+
+					continue
+				}
+			}
+
+			p.log.Warn("external plugin failed, restarting", zap.Error(reason))
+
+			for {
+				if restarts >= p.opts.MaxRestarts {
+					errCh <- fmt.Errorf("external plugin `%s`: %d restarts in a row: %w", p.cfg.Name, restarts, reason)
+
+					return
+				}
+
+				delay := p.opts.RestartBackoff
+				for i := 0; i < restarts && delay < maxRestartBackoff; i++ {
+					delay *= 2
+				}
+
+				if delay > maxRestartBackoff {
+					delay = maxRestartBackoff
+				}
+
+				restarts++
+
+				select {
+				case <-p.stop:
+					return
+				case <-time.After(delay):
+				}
+
+				p.shutdown()
+
+				if reason = p.start(); reason == nil {
+					break
+				}
+
+				p.log.Warn("external plugin restart failed", zap.Error(reason))
+			}
+		}
+	}()
+}
+
+// close stops watching and shuts the plugin down.
+func (p *process) close() {
+	close(p.stop)
+	p.wg.Wait()
+	p.shutdown()
+}
+
+func (p *process) health() error {
+	var ok bool
+
+	err := p.call(protocol.MethodHealth, true, &ok)
+	if err == nil && !ok {
+		err = errors.Str("health check failed")
+	}
+
+	p.mu.Lock()
+	p.healthy = err == nil
+	p.mu.Unlock()
+
+	return err
+}
+
+// call calls the plugin method with the configured timeout.
+func (p *process) call(method string, args, reply interface{}) error {
+	p.mu.RLock()
+	client := p.client
+	p.mu.RUnlock()
+
+	if client == nil {
+		return errors.Str("plugin is not connected")
+	}
+
+	return callTimeout(client, method, args, reply, p.opts.Timeout)
+}
+
+// callTimeout calls the method and stops waiting for the reply after the timeout.
+func callTimeout(client *netRpc.Client, method string, args, reply interface{}, timeout time.Duration) error {
+	call := client.Go(method, args, reply, make(chan *netRpc.Call, 1))
+
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-time.After(timeout):
+		return fmt.Errorf("%s: timed out after %s", method, timeout)
+	}
+}
+
+// proxy calls the plugin RPC method declared in the manifest with the call timeout.
+func (p *process) proxy(method string, payload []byte) ([]byte, error) {
+	p.mu.RLock()
+	client, manifest := p.client, p.manifest
+	p.mu.RUnlock()
+
+	declared := false
+	for _, m := range manifest.RPC {
+		if m == method {
+			declared = true
+
+			break
+		}
+	}
+
+	if !declared {
+		return nil, fmt.Errorf("method `%s` is not registered by the plugin `%s`", method, p.cfg.Name)
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("plugin `%s` is not connected", p.cfg.Name)
+	}
+
+	var out []byte
+
+	err := callTimeout(client, method, payload, &out, p.opts.CallTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (p *process) exitedCh() <-chan struct{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.exited
+}
+
+func (p *process) state() (protocol.Manifest, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.manifest, p.healthy
+}
@@ -0,0 +1,40 @@
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+
+	protocol "github.com/roadrunner-server/roadrunner/v2/pkg/external"
+)
+
+type rpc struct {
+	plugin *Plugin
+}
+
+// List returns names of the external plugins.
+func (r *rpc) List(_ bool, list *[]string) error {
+	*list = make([]string, 0, len(r.plugin.procs))
+
+	for _, proc := range r.plugin.procs {
+		*list = append(*list, proc.cfg.Name)
+	}
+
+	return nil
+}
+
+// Call proxies the call to the RPC method registered by the external plugin.
+func (r *rpc) Call(in *protocol.CallRequest, out *json.RawMessage) error {
+	proc := r.plugin.process(in.Plugin)
+	if proc == nil {
+		return fmt.Errorf("no such external plugin `%s`", in.Plugin)
+	}
+
+	res, err := proc.proxy(in.Method, in.Payload)
+	if err != nil {
+		return err
+	}
+
+	*out = res
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package har
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Redacted replaces values of the redacted headers and query parameters.
+const Redacted string = "[REDACTED]"
+
+// redact lists the headers (lower case) and query parameters with the redacted values.
+type redact struct {
+	headers map[string]struct{}
+	query   map[string]struct{}
+}
+
+func newRedact(headers, query []string) *redact {
+	r := &redact{headers: make(map[string]struct{}, len(headers)), query: make(map[string]struct{}, len(query))}
+
+	for _, h := range headers {
+		r.headers[strings.ToLower(h)] = struct{}{}
+	}
+
+	for _, q := range query {
+		r.query[q] = struct{}{}
+	}
+
+	return r
+}
+
+// limitedBuffer keeps the first limit bytes of the written data and counts the whole size.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+	size  int64
+}
+
+func (b *limitedBuffer) add(p []byte) {
+	b.size += int64(len(p))
+
+	if rest := b.limit - b.buf.Len(); rest > 0 {
+		if len(p) > rest {
+			p = p[:rest]
+		}
+
+		b.buf.Write(p)
+	}
+}
+
+func (b *limitedBuffer) truncated() bool {
+	return b.size > int64(b.buf.Len())
+}
+
+// text returns the captured data as text, binary data is base64 encoded.
+func (b *limitedBuffer) text() (string, string) {
+	if utf8.Valid(b.buf.Bytes()) {
+		return b.buf.String(), ""
+	}
+
+	return base64.StdEncoding.EncodeToString(b.buf.Bytes()), "base64"
+}
+
+// body captures the request body while the handler reads it.
+type body struct {
+	io.ReadCloser
+	limitedBuffer
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.add(p[:n])
+
+	return n, err
+}
+
+// writer captures the response.
+type writer struct {
+	http.ResponseWriter
+	limitedBuffer
+	status int
+}
+
+func (w *writer) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	w.add(p)
+
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher for the streamed responses.
+func (w *writer) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for the upgraded connections, the data sent over them is not captured.
+func (w *writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the original writer for http.ResponseController.
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// entry creates the HAR entry of the served request.
+func entry(r *http.Request, b *body, w *writer, start time.Time, redact *redact) *Entry {
+	elapsed := float64(time.Since(start).Microseconds()) / 1000
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	query := r.URL.Query()
+	uri := r.URL.RequestURI()
+
+	if q := redactQuery(query, redact.query); q != nil {
+		u := *r.URL
+		u.RawQuery = q.Encode()
+		uri = u.RequestURI()
+		query = q
+	}
+
+	req := &Request{
+		Method:      r.Method,
+		URL:         scheme + "://" + r.Host + uri,
+		HTTPVersion: r.Proto,
+		Headers:     headers(r.Header, redact.headers),
+		QueryString: make([]NameValue, 0, len(query)),
+		Cookies:     []NameValue{},
+		HeadersSize: -1,
+		BodySize:    b.size,
+	}
+
+	for name, values := range query {
+		for _, v := range values {
+			req.QueryString = append(req.QueryString, NameValue{Name: name, Value: v})
+		}
+	}
+
+	if b.size > 0 {
+		text, encoding := b.text()
+		req.PostData = &PostData{
+			MimeType:  r.Header.Get("Content-Type"),
+			Text:      text,
+			Encoding:  encoding,
+			Truncated: b.truncated(),
+		}
+	}
+
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	text, encoding := w.text()
+
+	return &Entry{
+		StartedDateTime: start,
+		Time:            elapsed,
+		Request:         req,
+		Response: &Response{
+			Status:      status,
+			StatusText:  http.StatusText(status),
+			HTTPVersion: r.Proto,
+			Headers:     headers(w.Header(), redact.headers),
+			Cookies:     []NameValue{},
+			Content: &Content{
+				Size:      w.size,
+				MimeType:  w.Header().Get("Content-Type"),
+				Text:      text,
+				Encoding:  encoding,
+				Truncated: w.truncated(),
+			},
+			HeadersSize: -1,
+			BodySize:    w.size,
+		},
+		Timings: &Timings{Wait: elapsed},
+	}
+}
+
+func headers(h http.Header, redact map[string]struct{}) []NameValue {
+	out := make([]NameValue, 0, len(h))
+
+	for name, values := range h {
+		_, redacted := redact[strings.ToLower(name)]
+
+		for _, v := range values {
+			if redacted {
+				v = Redacted
+			}
+
+			out = append(out, NameValue{Name: name, Value: v})
+		}
+	}
+
+	return out
+}
+
+// redactQuery returns the query with the redacted values or nil when there is nothing to redact.
+func redactQuery(query url.Values, redact map[string]struct{}) url.Values {
+	var out url.Values
+
+	for name, values := range query {
+		if _, ok := redact[name]; !ok {
+			continue
+		}
+
+		if out == nil {
+			out = make(url.Values, len(query))
+			for k, v := range query {
+				out[k] = v
+			}
+		}
+
+		redacted := make([]string, len(values))
+		for i := range redacted {
+			redacted[i] = Redacted
+		}
+
+		out[name] = redacted
+	}
+
+	return out
+}
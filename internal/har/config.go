@@ -0,0 +1,66 @@
+package har
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config of the HAR capture.
+type Config struct {
+	// Dir to write HAR files to.
+	Dir string `mapstructure:"dir"`
+	// SampleRate is the share of captured requests (0..1].
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// MaxBodySize of the captured request and response bodies in bytes, longer bodies are truncated.
+	MaxBodySize int `mapstructure:"max_body_size"`
+	// MaxEntries in a single file, the next file is started after that.
+	MaxEntries int `mapstructure:"max_entries"`
+	// MaxSize of a single file in bytes, the next file is started after that, 0 means no limit.
+	MaxSize int64 `mapstructure:"max_size"`
+	// MaxFiles kept in the directory, the oldest ones are removed when a new file is started, 0 means no limit.
+	MaxFiles int `mapstructure:"max_files"`
+	// FlushInterval between appends of the captured entries to the current file.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// RedactHeaders values are replaced with Redacted, in addition to the default ones.
+	RedactHeaders []string `mapstructure:"redact_headers"`
+	// RedactQuery parameters values are replaced with Redacted in the URL and the query string.
+	RedactQuery []string `mapstructure:"redact_query"`
+}
+
+// InitDefaults sets default values.
+func (c *Config) InitDefaults() {
+	if c.Dir == "" {
+		c.Dir = "har"
+	}
+
+	if c.SampleRate == 0 {
+		c.SampleRate = 1
+	}
+
+	if c.MaxBodySize == 0 {
+		c.MaxBodySize = 64 * 1024
+	}
+
+	if c.MaxEntries == 0 {
+		c.MaxEntries = 1000
+	}
+
+	if c.FlushInterval == 0 {
+		c.FlushInterval = time.Second * 10
+	}
+
+	c.RedactHeaders = append(c.RedactHeaders, "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie")
+}
+
+// Valid validates the configuration.
+func (c *Config) Valid() error {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("sample_rate should be in the (0..1] range, got %v", c.SampleRate)
+	}
+
+	if c.MaxBodySize < 0 || c.MaxEntries < 0 || c.MaxSize < 0 || c.MaxFiles < 0 {
+		return fmt.Errorf("max_body_size, max_entries, max_size and max_files should not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// HAR 1.2 document (http://www.softwareishard.com/blog/har-12-spec/), fields prefixed with `_` are custom.
+type HAR struct {
+	Log *Log `json:"log"`
+}
+
+// Log of the captured entries.
+type Log struct {
+	Version string   `json:"version"`
+	Creator *Creator `json:"creator"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Creator of the log.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a single request with the response.
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	// Time of the request in milliseconds.
+	Time     float64   `json:"time"`
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+	Cache    struct{}  `json:"cache"`
+	Timings  *Timings  `json:"timings"`
+}
+
+// NameValue is a header, query parameter or cookie.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Request captured by the middleware.
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	Cookies     []NameValue `json:"cookies"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+	PostData    *PostData   `json:"postData,omitempty"`
+}
+
+// PostData is the request body, binary bodies are base64 encoded.
+type PostData struct {
+	MimeType  string `json:"mimeType"`
+	Text      string `json:"text"`
+	Encoding  string `json:"_encoding,omitempty"`
+	Truncated bool   `json:"_truncated,omitempty"`
+}
+
+// Response captured by the middleware.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []NameValue `json:"headers"`
+	Cookies     []NameValue `json:"cookies"`
+	Content     *Content    `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// Content is the response body, binary bodies are base64 encoded.
+type Content struct {
+	Size      int64  `json:"size"`
+	MimeType  string `json:"mimeType"`
+	Text      string `json:"text"`
+	Encoding  string `json:"encoding,omitempty"`
+	Truncated bool   `json:"_truncated,omitempty"`
+}
+
+// Timings of the request in milliseconds, the whole time is spent waiting for the handler.
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Body returns the decoded request body.
+func (p *PostData) Body() ([]byte, error) {
+	return decode(p.Text, p.Encoding)
+}
+
+// Body returns the decoded response body.
+func (c *Content) Body() ([]byte, error) {
+	return decode(c.Text, c.Encoding)
+}
+
+// Read reads the HAR file.
+func Read(path string) (*HAR, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &HAR{}
+	if err = json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+
+	if h.Log == nil {
+		h.Log = &Log{}
+	}
+
+	return h, nil
+}
+
+func decode(text, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(text)
+	}
+
+	return []byte(text), nil
+}
@@ -0,0 +1,126 @@
+// Package har captures sampled HTTP requests and responses into HAR files (`har` http middleware), the files are
+// replayed with `rr http replay`.
+package har
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/roadrunner-server/api/v2/plugins/config"
+	"github.com/roadrunner-server/errors"
+	"go.uber.org/zap"
+)
+
+// PluginName is the name of the plugin, its configuration section and the middleware.
+const PluginName string = "har"
+
+// Plugin is the HAR capture middleware.
+type Plugin struct {
+	cfg    *Config
+	log    *zap.Logger
+	redact *redact
+	rec    *recorder
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Init reads the configuration.
+func (p *Plugin) Init(cfg config.Configurer, log *zap.Logger) error {
+	const op = errors.Op("har_plugin_init")
+
+	if !cfg.Has(PluginName) {
+		return errors.E(op, errors.Disabled)
+	}
+
+	err := cfg.UnmarshalKey(PluginName, &p.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if p.cfg == nil {
+		p.cfg = &Config{}
+	}
+
+	p.cfg.InitDefaults()
+
+	if err = p.cfg.Valid(); err != nil {
+		return errors.E(op, err)
+	}
+
+	p.redact = newRedact(p.cfg.RedactHeaders, p.cfg.RedactQuery)
+	p.log = log
+	p.rec = newRecorder(p.cfg, cfg.RRVersion(), log)
+	p.rnd = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	p.stop = make(chan struct{})
+
+	return nil
+}
+
+// Serve starts writing the captured entries.
+func (p *Plugin) Serve() chan error {
+	errCh := make(chan error, 1)
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		p.rec.run(p.stop)
+	}()
+
+	return errCh
+}
+
+// Stop writes the captured entries.
+func (p *Plugin) Stop() error {
+	close(p.stop)
+	p.wg.Wait()
+
+	return nil
+}
+
+// Name returns the plugin name.
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// Middleware captures sampled requests.
+func (p *Plugin) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !p.sampled() {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		start := time.Now()
+
+		b := &body{ReadCloser: r.Body, limitedBuffer: limitedBuffer{limit: p.cfg.MaxBodySize}}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = b
+		}
+
+		rw := &writer{ResponseWriter: w, limitedBuffer: limitedBuffer{limit: p.cfg.MaxBodySize}}
+
+		next.ServeHTTP(rw, r)
+
+		p.rec.add(entry(r, b, rw, start, p.redact))
+	})
+}
+
+func (p *Plugin) sampled() bool {
+	if p.cfg.SampleRate >= 1 {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.rnd.Float64() < p.cfg.SampleRate
+}
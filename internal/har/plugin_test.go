@@ -0,0 +1,231 @@
+package har
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/config/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newPlugin(t *testing.T, cfg string) *Plugin {
+	c := &config.Plugin{Type: "yaml", ReadInCfg: []byte(cfg)}
+	require.NoError(t, c.Init())
+
+	p := &Plugin{}
+	require.NoError(t, p.Init(c, zap.NewNop()))
+
+	return p
+}
+
+func TestPlugin_Middleware(t *testing.T) {
+	dir := t.TempDir()
+	p := newPlugin(t, "har:\n  dir: "+dir+"\n  max_body_size: 8\n  max_entries: 2\n  redact_headers: [X-Token]\n"+
+		"  redact_query: [token]\n")
+	_ = p.Serve()
+
+	h := p.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(append([]byte("echo:"), data...))
+	}))
+
+	for _, b := range []string{"hi", "long request body", "\xff\xfe"} {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/path?a=1&token=secret", strings.NewReader(b))
+		req.Header.Set("X-Token", "secret")
+		req.Header.Set("Accept", "text/plain")
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, "echo:"+b, rec.Body.String())
+	}
+
+	require.NoError(t, p.Stop())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.har"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	var entries []*Entry
+
+	for _, f := range files {
+		h, errR := Read(f)
+		require.NoError(t, errR)
+		assert.Equal(t, "1.2", h.Log.Version)
+
+		entries = append(entries, h.Log.Entries...)
+	}
+
+	require.Len(t, entries, 3)
+
+	e := entries[0]
+	assert.Equal(t, "http://example.com/path?a=1&token=%5BREDACTED%5D", e.Request.URL)
+	assert.ElementsMatch(t, []NameValue{{Name: "a", Value: "1"}, {Name: "token", Value: Redacted}},
+		e.Request.QueryString)
+	assert.Contains(t, e.Request.Headers, NameValue{Name: "X-Token", Value: Redacted})
+	assert.Contains(t, e.Request.Headers, NameValue{Name: "Accept", Value: "text/plain"})
+	assert.Contains(t, e.Response.Headers, NameValue{Name: "Set-Cookie", Value: Redacted})
+	assert.Equal(t, http.StatusCreated, e.Response.Status)
+	assert.Equal(t, "hi", e.Request.PostData.Text)
+	assert.Equal(t, "echo:hi", e.Response.Content.Text)
+
+	// bodies are truncated to max_body_size
+	e = entries[1]
+	assert.Equal(t, "long req", e.Request.PostData.Text)
+	assert.True(t, e.Request.PostData.Truncated)
+	assert.EqualValues(t, 17, e.Request.BodySize)
+	assert.Equal(t, "echo:lon", e.Response.Content.Text)
+	assert.True(t, e.Response.Content.Truncated)
+
+	// binary bodies are base64 encoded
+	e = entries[2]
+	assert.Equal(t, "base64", e.Request.PostData.Encoding)
+
+	body, err := e.Request.PostData.Body()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("\xff\xfe"), body)
+}
+
+func TestPlugin_Sampling(t *testing.T) {
+	dir := t.TempDir()
+	p := newPlugin(t, "har:\n  dir: "+dir+"\n  sample_rate: 0.1\n")
+	_ = p.Serve()
+
+	h := p.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	for i := 0; i < 1000; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	require.NoError(t, p.Stop())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.har"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	h2, err := Read(files[0])
+	require.NoError(t, err)
+	assert.InDelta(t, 100, len(h2.Log.Entries), 50)
+	assert.Nil(t, h2.Log.Entries[0].Request.PostData)
+}
+
+func TestPlugin_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	// every entry fills the file
+	p := newPlugin(t, "har:\n  dir: "+dir+"\n  max_size: 100\n  max_files: 2\n  flush_interval: 10ms\n")
+	_ = p.Serve()
+
+	h := p.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	for i := 0; i < 3; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%d", i), nil))
+
+		// the entries are written by the writer goroutine
+		assert.Eventually(t, func() bool {
+			files, _ := filepath.Glob(filepath.Join(dir, "*.har"))
+
+			for _, f := range files {
+				if h, err := Read(f); err == nil && len(h.Log.Entries) == 1 &&
+					h.Log.Entries[0].Request.URL == fmt.Sprintf("http://example.com/%d", i) {
+					return true
+				}
+			}
+
+			return false
+		}, time.Second*5, time.Millisecond*10)
+	}
+
+	require.NoError(t, p.Stop())
+
+	// the oldest file is removed
+	files, err := filepath.Glob(filepath.Join(dir, "*.har"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	urls := make([]string, 0, 2)
+
+	for _, f := range files {
+		h, errR := Read(f)
+		require.NoError(t, errR)
+		require.Len(t, h.Log.Entries, 1)
+
+		urls = append(urls, h.Log.Entries[0].Request.URL)
+	}
+
+	assert.ElementsMatch(t, []string{"http://example.com/1", "http://example.com/2"}, urls)
+}
+
+func TestPlugin_Append(t *testing.T) {
+	dir := t.TempDir()
+	p := newPlugin(t, "har:\n  dir: "+dir+"\n  flush_interval: 10ms\n")
+	_ = p.Serve()
+
+	h := p.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	// the current file is valid after every write
+	for i := 1; i <= 3; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Eventually(t, func() bool {
+			files, _ := filepath.Glob(filepath.Join(dir, "*.har"))
+			if len(files) != 1 {
+				return false
+			}
+
+			h, err := Read(files[0])
+
+			return err == nil && len(h.Log.Entries) == i
+		}, time.Second*5, time.Millisecond*10)
+	}
+
+	require.NoError(t, p.Stop())
+}
+
+func TestWriter_Hijack(t *testing.T) {
+	p := newPlugin(t, "har:\n  dir: "+t.TempDir()+"\n")
+	_ = p.Serve()
+
+	defer func() { _ = p.Stop() }()
+
+	srv := httptest.NewServer(p.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Implements(t, (*interface{ Unwrap() http.ResponseWriter })(nil), w)
+
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: close\r\n\r\n")
+		_ = buf.Flush()
+		_ = conn.Close()
+	})))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL) //nolint:noctx
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+}
+
+func TestPlugin_Disabled(t *testing.T) {
+	c := &config.Plugin{Type: "yaml", ReadInCfg: []byte("http:\n  address: 127.0.0.1:8080\n")}
+	require.NoError(t, c.Init())
+
+	assert.Error(t, (&Plugin{}).Init(c, zap.NewNop()))
+}
+
+func TestConfig_Valid(t *testing.T) {
+	for _, cfg := range []*Config{{SampleRate: 2}, {SampleRate: -1}, {MaxBodySize: -1}, {MaxSize: -1}, {MaxFiles: -1}} {
+		assert.Error(t, cfg.Valid())
+	}
+}
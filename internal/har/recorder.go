@@ -0,0 +1,224 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// queueSize is the number of entries waiting for the writer, the middleware drops the entries after that
+	queueSize int = 1024
+	// closes the entries array and the document, rewritten after every write, so the file is always valid
+	trailer     string = "\n]}}\n"
+	filePattern string = "rr-*.har"
+)
+
+// recorder writes the entries into HAR files. The middleware only queues the entries, a single writer goroutine
+// appends them to the current file every flush interval and starts a new file when the current one is full.
+type recorder struct {
+	cfg     *Config
+	version string
+	log     *zap.Logger
+
+	entries chan *Entry
+	// entries dropped because the queue was full, reported by the writer
+	dropped uint64
+
+	// the fields below are owned by the writer goroutine
+	file *os.File
+	// offset of the trailer in the current file
+	end int64
+	// entries in the current file, including the pending ones
+	count int
+	seq   int
+	// encoded entries not written yet
+	pending bytes.Buffer
+}
+
+func newRecorder(cfg *Config, version string, log *zap.Logger) *recorder {
+	return &recorder{cfg: cfg, version: version, log: log, entries: make(chan *Entry, queueSize)}
+}
+
+// add queues the entry, it never blocks the request.
+func (r *recorder) add(e *Entry) {
+	select {
+	case r.entries <- e:
+	default:
+		atomic.AddUint64(&r.dropped, 1)
+	}
+}
+
+// run writes the queued entries until the stop channel is closed, the rest of the queue is written after that.
+func (r *recorder) run(stop <-chan struct{}) {
+	t := time.NewTicker(r.cfg.FlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case e := <-r.entries:
+			r.encode(e)
+		case <-t.C:
+			r.flush()
+		case <-stop:
+			for {
+				select {
+				case e := <-r.entries:
+					r.encode(e)
+				default:
+					r.flush()
+					r.close()
+
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *recorder) encode(e *Entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		r.log.Error("failed to encode the HAR entry", zap.Error(err))
+
+		return
+	}
+
+	if r.count > 0 {
+		r.pending.WriteString(",\n")
+	}
+
+	r.pending.Write(data)
+	r.count++
+
+	// the file is full, the next entry starts a new one
+	if r.count >= r.cfg.MaxEntries || (r.cfg.MaxSize > 0 && r.end+int64(r.pending.Len()) >= r.cfg.MaxSize) {
+		r.flush()
+		r.close()
+	}
+}
+
+// flush appends the pending entries to the current file.
+func (r *recorder) flush() {
+	if n := atomic.SwapUint64(&r.dropped, 0); n > 0 {
+		r.log.Warn("HAR entries dropped, the writer is too slow", zap.Uint64("count", n))
+	}
+
+	if r.pending.Len() == 0 {
+		return
+	}
+
+	err := r.write()
+	if err != nil {
+		r.log.Error("failed to write the HAR file", zap.Error(err))
+		// the entries are lost, the next entry starts a new file
+		r.close()
+	}
+}
+
+func (r *recorder) write() error {
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+
+	r.pending.WriteString(trailer)
+
+	// the trailer is overwritten by the entries and written again after them
+	n, err := r.file.WriteAt(r.pending.Bytes(), r.end)
+	if err != nil {
+		return err
+	}
+
+	r.end += int64(n - len(trailer))
+	r.pending.Reset()
+
+	return nil
+}
+
+// open creates the next file with the log header and removes the oldest files over the max_files limit.
+func (r *recorder) open() error {
+	creator, err := json.Marshal(&Creator{Name: "RoadRunner", Version: r.version})
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(r.cfg.Dir, 0o755); err != nil {
+		return err
+	}
+
+	r.rotate()
+
+	r.seq++
+	name := filepath.Join(r.cfg.Dir, fmt.Sprintf("rr-%s-%d.har", time.Now().Format("20060102-150405"), r.seq))
+
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	header := `{"log":{"version":"1.2","creator":` + string(creator) + ",\"entries\":[\n"
+	if _, err = f.WriteString(header); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	r.file, r.end = f, int64(len(header))
+
+	return nil
+}
+
+// rotate removes the oldest HAR files, so that max_files are kept with the new one.
+func (r *recorder) rotate() {
+	if r.cfg.MaxFiles == 0 {
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(r.cfg.Dir, filePattern))
+	if err != nil || len(files) < r.cfg.MaxFiles {
+		return
+	}
+
+	modified := make(map[string]time.Time, len(files))
+
+	for _, f := range files {
+		if info, errS := os.Stat(f); errS == nil {
+			modified[f] = info.ModTime()
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if modified[files[i]].Equal(modified[files[j]]) {
+			return files[i] < files[j]
+		}
+
+		return modified[files[i]].Before(modified[files[j]])
+	})
+
+	for _, f := range files[:len(files)-r.cfg.MaxFiles+1] {
+		if err = os.Remove(f); err != nil {
+			r.log.Warn("failed to remove the HAR file", zap.String("file", f), zap.Error(err))
+		}
+	}
+}
+
+// close closes the current file, the next entry starts a new one.
+func (r *recorder) close() {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			r.log.Error("failed to close the HAR file", zap.Error(err))
+		}
+	}
+
+	r.file, r.end, r.count = nil, 0, 0
+	r.pending.Reset()
+}
@@ -9,6 +9,9 @@ import (
 	"net/rpc"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/envconfig"
 
 	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
 	rpcPlugin "github.com/roadrunner-server/rpc/v2"
@@ -18,6 +21,8 @@ import (
 const (
 	prefix string = "rr"
 	rpcKey string = "rpc.listen"
+	// unreachable (blackholed) hosts fail after that
+	dialTimeout time.Duration = time.Second * 5
 )
 
 // NewClient creates client ONLY for internal usage (communication between our application with RR side).
@@ -29,7 +34,7 @@ func NewClient(cfg string, flags []string) (*rpc.Client, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.SetConfigFile(cfg)
 
-	err := v.ReadInConfig()
+	err := readConfig(v, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +72,23 @@ func NewClient(cfg string, flags []string) (*rpc.Client, error) {
 	return rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn)), nil
 }
 
+// readConfig reads the configuration file, missing file is replaced with the configuration built from the RR_
+// environment variables (when there are any).
+func readConfig(v *viper.Viper, cfg string) error {
+	if _, err := os.Stat(cfg); os.IsNotExist(err) {
+		values, _, errB := envconfig.Build(os.Environ())
+		if errB != nil {
+			return errB
+		}
+
+		if len(values) > 0 {
+			return v.MergeConfigMap(values)
+		}
+	}
+
+	return v.ReadInConfig()
+}
+
 // Dialer creates rpc socket Dialer.
 func Dialer(addr string) (net.Conn, error) {
 	dsn := strings.Split(addr, "://")
@@ -74,7 +96,7 @@ func Dialer(addr string) (net.Conn, error) {
 		return nil, errors.New("invalid socket DSN (tcp://:6001, unix://file.sock)")
 	}
 
-	return net.Dial(dsn[0], dsn[1])
+	return net.DialTimeout(dsn[0], dsn[1], dialTimeout)
 }
 
 func parseFlag(flag string) (string, string, error) {
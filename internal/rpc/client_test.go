@@ -96,3 +96,19 @@ func TestNewClient_SuccessfullyConnectedEnvDollarSyntax(t *testing.T) {
 
 	defer func() { assert.NoError(t, c.Close()) }()
 }
+
+// no configuration file, the configuration is built from the environment
+func TestNewClient_SuccessfullyConnectedEnvOnly(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:55557")
+	assert.NoError(t, err)
+
+	defer func() { assert.NoError(t, l.Close()) }()
+
+	t.Setenv("RR_RPC_LISTEN", "tcp://127.0.0.1:55557")
+	c, err := rpc.NewClient("test/config_missing.yaml", nil)
+
+	assert.NotNil(t, c)
+	assert.NoError(t, err)
+
+	defer func() { assert.NoError(t, c.Close()) }()
+}
@@ -0,0 +1,204 @@
+package rpc
+
+import (
+	"fmt"
+	"net/rpc"
+	"strings"
+	"sync"
+	"time"
+
+	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
+	"github.com/spf13/viper"
+)
+
+const (
+	inventoryKey string = "instances"
+	// CallTimeout is the default timeout of every call to the fleet instance.
+	CallTimeout time.Duration = time.Minute
+)
+
+// Caller calls RPC methods of the instance.
+type Caller interface {
+	Call(serviceMethod string, args any, reply any) error
+}
+
+// timeoutClient bounds every call with the timeout, so a wedged instance fails instead of blocking the whole run.
+type timeoutClient struct {
+	client  *rpc.Client
+	timeout time.Duration
+}
+
+// Call calls the method and stops waiting for the reply after the timeout.
+func (c *timeoutClient) Call(serviceMethod string, args any, reply any) error {
+	call := c.client.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-time.After(c.timeout):
+		return fmt.Errorf("%s: timed out after %s", serviceMethod, c.timeout)
+	}
+}
+
+// Instance is a single named RoadRunner instance reachable over RPC.
+type Instance struct {
+	// Name is shown in the aggregated tables, address is used when empty.
+	Name string `mapstructure:"name"`
+	// Address is an RPC socket DSN (tcp://127.0.0.1:6001, unix://rr.sock).
+	Address string `mapstructure:"address"`
+}
+
+// ParseTargets converts `--rpc` flag values into instances. Each value is either a plain socket DSN or a
+// `name=dsn` pair.
+func ParseTargets(targets []string) ([]Instance, error) {
+	instances := make([]Instance, 0, len(targets))
+
+	for _, t := range targets {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+
+		inst := Instance{Name: t, Address: t}
+
+		if name, addr, ok := strings.Cut(t, "="); ok {
+			if name == "" || addr == "" {
+				return nil, fmt.Errorf("invalid rpc target `%s`, usage: --rpc name=tcp://127.0.0.1:6001", t)
+			}
+
+			inst = Instance{Name: name, Address: addr}
+		}
+
+		instances = append(instances, inst)
+	}
+
+	return instances, nil
+}
+
+// LoadInventory reads the list of instances from the inventory file:
+//
+//	instances:
+//	  - name: web-1
+//	    address: tcp://10.0.0.1:6001
+func LoadInventory(path string) ([]Instance, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []Instance
+
+	err = v.UnmarshalKey(inventoryKey, &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("inventory `%s` contains no instances", path)
+	}
+
+	for i := 0; i < len(instances); i++ {
+		if instances[i].Address == "" {
+			return nil, fmt.Errorf("inventory `%s`: instance #%d has no address", path, i)
+		}
+
+		if instances[i].Name == "" {
+			instances[i].Name = instances[i].Address
+		}
+	}
+
+	return instances, nil
+}
+
+// Targets merges instances from the `--rpc` flags and the inventory file (if set). An empty result means that the
+// command should work with the single instance from the configuration file.
+func Targets(rpcFlags []string, inventory string) ([]Instance, error) {
+	instances, err := ParseTargets(rpcFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	if inventory != "" {
+		inv, errI := LoadInventory(inventory)
+		if errI != nil {
+			return nil, errI
+		}
+
+		instances = append(instances, inv...)
+	}
+
+	seen := make(map[string]struct{}, len(instances))
+	for _, inst := range instances {
+		if _, ok := seen[inst.Name]; ok {
+			return nil, fmt.Errorf("duplicate instance name `%s`", inst.Name)
+		}
+
+		seen[inst.Name] = struct{}{}
+	}
+
+	return instances, nil
+}
+
+// Connect creates RPC client connected to the instance.
+func Connect(inst Instance) (*rpc.Client, error) {
+	conn, err := Dialer(inst.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn)), nil
+}
+
+// Each concurrently connects to every instance and calls fn with the connected client, every call is bounded by the
+// timeout. Failures are collected per instance (errs[i] belongs to instances[i]), so one unreachable or wedged host does
+// not abort (or block) the whole run.
+func Each(instances []Instance, timeout time.Duration, fn func(inst Instance, client Caller) error) []error {
+	errs := make([]error, len(instances))
+
+	var wg sync.WaitGroup
+	wg.Add(len(instances))
+
+	for i := 0; i < len(instances); i++ {
+		go func(i int) {
+			defer wg.Done()
+
+			client, err := Connect(instances[i])
+			if err != nil {
+				errs[i] = err
+
+				return
+			}
+
+			defer func() { _ = client.Close() }()
+
+			errs[i] = fn(instances[i], &timeoutClient{client: client, timeout: timeout})
+		}(i)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// Summarize reports failed instances, an error is returned when any of them failed.
+func Summarize(instances []Instance, errs []error, report func(inst Instance, err error)) error {
+	failed := 0
+
+	for i := 0; i < len(errs); i++ {
+		if errs[i] == nil {
+			continue
+		}
+
+		failed++
+		report(instances[i], errs[i])
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d instances failed", failed, len(instances))
+	}
+
+	return nil
+}
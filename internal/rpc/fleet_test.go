@@ -0,0 +1,114 @@
+package rpc_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/rpc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargets(t *testing.T) {
+	instances, err := rpc.ParseTargets([]string{"tcp://127.0.0.1:6001", "web-2=tcp://127.0.0.1:6002", " "})
+	require.NoError(t, err)
+
+	assert.Equal(t, []rpc.Instance{
+		{Name: "tcp://127.0.0.1:6001", Address: "tcp://127.0.0.1:6001"},
+		{Name: "web-2", Address: "tcp://127.0.0.1:6002"},
+	}, instances)
+
+	_, err = rpc.ParseTargets([]string{"=tcp://127.0.0.1:6001"})
+	assert.Error(t, err)
+}
+
+func TestLoadInventory(t *testing.T) {
+	instances, err := rpc.LoadInventory("test/inventory.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []rpc.Instance{
+		{Name: "web-1", Address: "tcp://127.0.0.1:55557"},
+		{Name: "tcp://127.0.0.1:55558", Address: "tcp://127.0.0.1:55558"},
+	}, instances)
+
+	_, err = rpc.LoadInventory("test/config_rpc_empty.yaml")
+	assert.Error(t, err)
+}
+
+func TestTargets_Duplicates(t *testing.T) {
+	_, err := rpc.Targets([]string{"web-1=tcp://127.0.0.1:6001"}, "test/inventory.yaml")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate instance name")
+}
+
+func TestEach_PartialFailure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:55557")
+	require.NoError(t, err)
+
+	defer func() { assert.NoError(t, l.Close()) }()
+
+	instances, err := rpc.LoadInventory("test/inventory.yaml")
+	require.NoError(t, err)
+
+	var called []string
+
+	errs := rpc.Each(instances, time.Second, func(inst rpc.Instance, _ rpc.Caller) error {
+		called = append(called, inst.Name)
+
+		return nil
+	})
+
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.Equal(t, []string{"web-1"}, called)
+
+	var reported []string
+
+	// a partial failure is a failure
+	err = rpc.Summarize(instances, errs, func(inst rpc.Instance, _ error) {
+		reported = append(reported, inst.Name)
+	})
+	assert.EqualError(t, err, "1 of 2 instances failed")
+	assert.Equal(t, []string{"tcp://127.0.0.1:55558"}, reported)
+
+	assert.Error(t, rpc.Summarize(instances, []error{errors.New("foo"), errors.New("bar")}, func(rpc.Instance, error) {}))
+	assert.NoError(t, rpc.Summarize(instances, []error{nil, nil}, func(rpc.Instance, error) {}))
+}
+
+func TestEach_Timeout(t *testing.T) {
+	// accepts connections, but never replies
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { assert.NoError(t, l.Close()) }()
+
+	go func() {
+		for {
+			conn, errA := l.Accept()
+			if errA != nil {
+				return
+			}
+
+			go func() { _, _ = io.Copy(io.Discard, conn) }()
+		}
+	}()
+
+	instances := []rpc.Instance{{Name: "wedged", Address: "tcp://" + l.Addr().String()}}
+
+	start := time.Now()
+	errs := rpc.Each(instances, time.Millisecond*100, func(_ rpc.Instance, client rpc.Caller) error {
+		var list []string
+
+		return client.Call("informer.List", true, &list)
+	})
+
+	require.Len(t, errs, 1)
+	require.Error(t, errs[0])
+	assert.Contains(t, errs[0].Error(), "timed out after 100ms")
+	assert.Less(t, time.Since(start), time.Second*5)
+}
@@ -0,0 +1,88 @@
+// Package sdnotify implements the systemd service notification protocol (sd_notify(3)): datagrams with newline
+// separated VARIABLE=value assignments sent to the unix socket from the NOTIFY_SOCKET environment variable.
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envSocket      string = "NOTIFY_SOCKET"
+	envWatchdogSec string = "WATCHDOG_USEC"
+	envWatchdogPID string = "WATCHDOG_PID"
+
+	// Ready tells the service manager that service startup is finished.
+	Ready string = "READY=1"
+	// Reloading tells the service manager that the service is reloading its configuration.
+	Reloading string = "RELOADING=1"
+	// Stopping tells the service manager that the service is beginning its shutdown.
+	Stopping string = "STOPPING=1"
+	// Watchdog updates the watchdog timestamp.
+	Watchdog string = "WATCHDOG=1"
+)
+
+// Status returns free-form status line shown by `systemctl status`.
+func Status(s string) string {
+	return "STATUS=" + s
+}
+
+// MainPID returns the main process PID assignment (used after the binary upgrade).
+func MainPID(pid int) string {
+	return "MAINPID=" + strconv.Itoa(pid)
+}
+
+// Enabled reports whether the process runs under the service manager which expects notifications.
+func Enabled() bool {
+	return os.Getenv(envSocket) != ""
+}
+
+// Notify sends states to the service manager. It returns false without error when notifications are not expected.
+func Notify(states ...string) (bool, error) {
+	addr := os.Getenv(envSocket)
+	if addr == "" {
+		return false, nil
+	}
+
+	// abstract namespace socket
+	if addr[0] == '@' {
+		addr = "\x00" + addr[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte(strings.Join(states, "\n")))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// WatchdogInterval returns interval of the watchdog pings: half of the WatchdogSec configured in the unit. Zero means
+// that the watchdog is disabled or is set for another process.
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv(envWatchdogSec)
+	if usec == "" {
+		return 0, nil
+	}
+
+	if pid := os.Getenv(envWatchdogPID); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(n) * time.Microsecond / 2, nil
+}
@@ -0,0 +1,68 @@
+package sdnotify_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/sdnotify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	assert.True(t, sdnotify.Enabled())
+
+	sent, err := sdnotify.Notify(sdnotify.Ready, sdnotify.Status("http: 4/4 workers ready"), sdnotify.MainPID(42))
+	require.NoError(t, err)
+	assert.True(t, sent)
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "READY=1\nSTATUS=http: 4/4 workers ready\nMAINPID=42", string(buf[:n]))
+}
+
+func TestNotify_Disabled(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	sent, err := sdnotify.Notify(sdnotify.Ready)
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+
+	d, err := sdnotify.WatchdogInterval()
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+
+	t.Setenv("WATCHDOG_USEC", "10000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+	d, err = sdnotify.WatchdogInterval()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second*5, d)
+
+	// watchdog for another process
+	t.Setenv("WATCHDOG_PID", "1")
+
+	d, err = sdnotify.WatchdogInterval()
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+}
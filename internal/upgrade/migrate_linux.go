@@ -0,0 +1,15 @@
+package upgrade
+
+import (
+	"os"
+	"strings"
+)
+
+// MigratesRequests reports whether the kernel moves the connections queued on a closed SO_REUSEPORT listener to the
+// other listeners of its group (net.ipv4.tcp_migrate_req, Linux 5.14+). Otherwise the connections queued on the
+// parent's sockets when it stops are reset.
+func MigratesRequests() bool {
+	data, err := os.ReadFile("/proc/sys/net/ipv4/tcp_migrate_req")
+
+	return err == nil && strings.TrimSpace(string(data)) == "1"
+}
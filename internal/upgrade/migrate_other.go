@@ -0,0 +1,8 @@
+//go:build !linux
+
+package upgrade
+
+// MigratesRequests reports false, the queued connections are moved between SO_REUSEPORT listeners only on Linux.
+func MigratesRequests() bool {
+	return false
+}
@@ -0,0 +1,55 @@
+package upgrade
+
+import (
+	"errors"
+)
+
+// PluginName is the name of the plugin and its RPC service.
+const PluginName string = "upgrade"
+
+// Plugin exposes the `upgrade.Upgrade` RPC method called by `rr upgrade`. The requests are passed to the serve command,
+// which performs the upgrade, so the plugin outlives the configuration reloads.
+type Plugin struct {
+	requests chan<- struct{}
+}
+
+// NewPlugin creates the plugin sending the upgrade requests to the channel.
+func NewPlugin(requests chan<- struct{}) *Plugin {
+	return &Plugin{requests: requests}
+}
+
+// Init is a no-op, the plugin has no configuration.
+func (p *Plugin) Init() error {
+	return nil
+}
+
+// Name returns the plugin name.
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// RPC returns associated rpc service.
+func (p *Plugin) RPC() interface{} {
+	return &rpc{requests: p.requests}
+}
+
+type rpc struct {
+	requests chan<- struct{}
+}
+
+// Upgrade requests the binary upgrade. It returns once the request is accepted: the running process stops after the
+// new one is serving.
+func (r *rpc) Upgrade(_ bool, accepted *bool) error {
+	if !supported {
+		return errors.New("binary upgrade is not supported on Windows")
+	}
+
+	select {
+	case r.requests <- struct{}{}:
+		*accepted = true
+
+		return nil
+	default:
+		return errors.New("upgrade is already in progress")
+	}
+}
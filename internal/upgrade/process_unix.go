@@ -0,0 +1,77 @@
+//go:build !windows
+
+package upgrade
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+const supported = true
+
+// Start re-executes the binary from the same path (the new one, if it was replaced on disk) with the same arguments
+// and waits for its readiness. The child is killed when it does not become ready in time.
+func Start(timeout time.Duration) (*os.Process, error) {
+	// on Linux the " (deleted)" suffix of the replaced binary is trimmed, so this is the path of the new one
+	bin, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = r.Close() }()
+
+	cmd := exec.Command(bin, os.Args[1:]...) //nolint:gosec
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{w}
+	cmd.Env = append(os.Environ(), EnvReadyFD+"="+strconv.Itoa(readyFD))
+
+	err = cmd.Start()
+	// the child has its own copy of the writer, so the reader gets EOF when the child exits
+	_ = w.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ready := make(chan error, 1)
+
+	go func() {
+		line, errR := bufio.NewReader(r).ReadString('\n')
+		if errR != nil || line != readyMsg+"\n" {
+			ready <- fmt.Errorf("new process %d exited before it became ready", cmd.Process.Pid)
+
+			return
+		}
+
+		ready <- nil
+	}()
+
+	select {
+	case err = <-ready:
+	case <-time.After(timeout):
+		err = fmt.Errorf("new process %d did not become ready in %s", cmd.Process.Pid, timeout)
+	}
+
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_, _ = cmd.Process.Wait()
+
+		return nil, err
+	}
+
+	// the child outlives the parent, release its resources
+	go func() { _ = cmd.Wait() }()
+
+	return cmd.Process, nil
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package upgrade
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const supported = false
+
+// Start is not supported on Windows: descriptors can't be inherited by the child.
+func Start(time.Duration) (*os.Process, error) {
+	return nil, errors.New("binary upgrade is not supported on Windows")
+}
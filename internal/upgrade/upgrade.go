@@ -0,0 +1,51 @@
+// Package upgrade implements zero-downtime binary upgrade. The running process starts the (new) binary with the same
+// arguments and environment and waits until the child reports that its container is serving. Plugins bind TCP
+// listeners with SO_REUSEPORT, so the child binds the same addresses while the parent is still accepting connections;
+// the parent stops only after the child is ready. Connections queued on the parent's sockets when it stops are moved
+// to the child's sockets by the kernel only with net.ipv4.tcp_migrate_req enabled (Linux 5.14+), otherwise they are
+// reset. The upgrade is requested via the `upgrade.Upgrade` RPC method (`rr upgrade`).
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	// EnvReadyFD is the file descriptor number of the readiness pipe inherited by the child.
+	EnvReadyFD string = "RR_UPGRADE_READY_FD"
+
+	readyMsg string = "ready"
+	// first ExtraFiles descriptor (after stdin, stdout and stderr)
+	readyFD int = 3
+)
+
+// Ready notifies the parent process (if this process was started by the upgrade) that the container is serving. It
+// is a no-op otherwise.
+func Ready() error {
+	v, ok := os.LookupEnv(EnvReadyFD)
+	if !ok {
+		return nil
+	}
+
+	// do not pass the descriptor to the next upgrade or to the workers
+	_ = os.Unsetenv(EnvReadyFD)
+
+	fd, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %w", EnvReadyFD, err)
+	}
+
+	f := os.NewFile(uintptr(fd), "upgrade-ready")
+	if f == nil {
+		return errors.New("invalid upgrade readiness descriptor")
+	}
+
+	defer func() { _ = f.Close() }()
+
+	_, err = f.WriteString(readyMsg + "\n")
+
+	return err
+}
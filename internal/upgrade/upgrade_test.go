@@ -0,0 +1,123 @@
+package upgrade_test
+
+import (
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/upgrade"
+
+	"github.com/roadrunner-server/tcplisten"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	envHelper = "RR_UPGRADE_TEST_HELPER"
+	// address bound by the parent
+	envAddr = "RR_UPGRADE_TEST_ADDR"
+)
+
+// TestMain turns the test binary into the upgraded child when the helper variable is set.
+func TestMain(m *testing.M) {
+	switch os.Getenv(envHelper) {
+	case "ready":
+		if err := upgrade.Ready(); err != nil {
+			os.Exit(2)
+		}
+
+		time.Sleep(time.Second)
+		os.Exit(0)
+	case "fail":
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	case "reuse":
+		reuse()
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestStart_Ready(t *testing.T) {
+	t.Setenv(envHelper, "ready")
+
+	p, err := upgrade.Start(time.Second * 10)
+	require.NoError(t, err)
+	assert.NotZero(t, p.Pid)
+}
+
+func TestStart_ChildExited(t *testing.T) {
+	t.Setenv(envHelper, "fail")
+
+	_, err := upgrade.Start(time.Second * 10)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exited before it became ready")
+}
+
+func TestStart_Timeout(t *testing.T) {
+	t.Setenv(envHelper, "hang")
+
+	_, err := upgrade.Start(time.Millisecond * 500)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "did not become ready")
+}
+
+// reuse binds the parent's address next to it, reports readiness and serves one connection.
+func reuse() {
+	cfg := tcplisten.Config{ReusePort: true}
+
+	ln, err := cfg.NewListener("tcp4", os.Getenv(envAddr))
+	if err != nil {
+		os.Exit(4)
+	}
+
+	if err = upgrade.Ready(); err != nil {
+		os.Exit(2)
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		os.Exit(5)
+	}
+
+	_, _ = conn.Write([]byte("child"))
+	_ = conn.Close()
+	os.Exit(0)
+}
+
+func TestStart_ReusePort(t *testing.T) {
+	cfg := tcplisten.Config{ReusePort: true}
+	ln, err := cfg.NewListener("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Setenv(envHelper, "reuse")
+	t.Setenv(envAddr, ln.Addr().String())
+
+	p, err := upgrade.Start(time.Second * 10)
+	require.NoError(t, err)
+
+	// the address is served by the child after the parent closed its listener
+	require.NoError(t, ln.Close())
+
+	conn, err := net.Dial("tcp4", ln.Addr().String())
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "child", string(data))
+
+	st, err := p.Wait()
+	if err == nil {
+		assert.True(t, st.Success())
+	}
+}
+
+func TestReady_NotChild(t *testing.T) {
+	assert.NoError(t, upgrade.Ready())
+}
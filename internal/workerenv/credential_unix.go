@@ -0,0 +1,52 @@
+//go:build !windows
+
+package workerenv
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// Credential runs the command from the user and group (by name), same as the server plugin.
+func Credential(cmd *exec.Cmd, usr, group string) error {
+	if usr == "" && group == "" {
+		return nil
+	}
+
+	uid, gid := os.Getuid(), os.Getgid()
+
+	if usr != "" {
+		u, err := user.Lookup(usr)
+		if err != nil {
+			return err
+		}
+
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return err
+		}
+
+		if gid, err = strconv.Atoi(u.Gid); err != nil {
+			return err
+		}
+	}
+
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return err
+		}
+
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return err
+		}
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)},
+	}
+
+	return nil
+}
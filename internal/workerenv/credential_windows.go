@@ -0,0 +1,18 @@
+//go:build windows
+
+package workerenv
+
+import (
+	"os/exec"
+
+	"github.com/roadrunner-server/errors"
+)
+
+// Credential is not supported on Windows, the server plugin ignores the user there as well.
+func Credential(_ *exec.Cmd, usr, group string) error {
+	if usr != "" || group != "" {
+		return errors.Str("server.user and server.group are not supported on windows")
+	}
+
+	return nil
+}
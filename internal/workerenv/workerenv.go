@@ -0,0 +1,78 @@
+// Package workerenv builds the environment of the processes started like the server plugin workers.
+package workerenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+
+	configImpl "github.com/roadrunner-server/config/v2"
+)
+
+// EnvRPC is the RPC address passed to the workers, when the rpc plugin is configured.
+const EnvRPC string = "RR_RPC"
+
+// Config is the worker part of the server plugin configuration.
+type Config struct {
+	Command string            `mapstructure:"command"`
+	User    string            `mapstructure:"user"`
+	Group   string            `mapstructure:"group"`
+	Env     map[string]string `mapstructure:"env"`
+	Relay   string            `mapstructure:"relay"`
+	// RPC is the rpc.listen address, empty when the rpc plugin is not configured
+	RPC string `mapstructure:"-"`
+}
+
+// Configurer is the part of the config plugin used to read the workers configuration.
+type Configurer interface {
+	Has(name string) bool
+	Get(name string) interface{}
+	UnmarshalKey(name string, out interface{}) error
+}
+
+// Read reads the server section and the RPC address with the env variables and overrides applied. Missing server
+// section results in the empty configuration.
+func Read(cfgFile string, override []string) (*Config, error) {
+	cfg := &configImpl.Plugin{Path: cfgFile, Prefix: "rr", Flags: override, Version: meta.Version()}
+	if err := cfg.Init(); err != nil {
+		return nil, err
+	}
+
+	return FromConfig(cfg)
+}
+
+// FromConfig reads the server section and the RPC address from the initialized configuration.
+func FromConfig(cfg Configurer) (*Config, error) {
+	c := &Config{}
+
+	if cfg.Has("server") {
+		if err := cfg.UnmarshalKey("server", c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Relay == "" {
+		c.Relay = "pipes"
+	}
+
+	c.RPC, _ = cfg.Get("rpc.listen").(string)
+
+	return c, nil
+}
+
+// Environ returns the current process environment with RR_RPC and server.env added, same as the server plugin.
+func (c *Config) Environ() []string {
+	env := os.Environ()
+
+	if c.RPC != "" {
+		env = append(env, EnvRPC+"="+c.RPC)
+	}
+
+	for k, v := range c.Env {
+		env = append(env, fmt.Sprintf("%s=%s", strings.ToUpper(k), os.Expand(v, os.Getenv)))
+	}
+
+	return env
+}
@@ -0,0 +1,30 @@
+package workerenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte("version: \"2.7\"\n"), 0o600))
+
+	// no server and rpc sections
+	c, err := Read(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "pipes", c.Relay)
+	assert.Empty(t, c.RPC)
+	assert.Equal(t, os.Environ(), c.Environ())
+
+	c, err = Read(cfg, []string{"server.env.foo=${RR_WORKERENV_TEST}", "rpc.listen=tcp://127.0.0.1:6001"})
+	require.NoError(t, err)
+
+	t.Setenv("RR_WORKERENV_TEST", "bar")
+
+	env := c.Environ()
+	assert.Equal(t, []string{EnvRPC + "=tcp://127.0.0.1:6001", "FOO=bar"}, env[len(env)-2:])
+}
@@ -0,0 +1,286 @@
+// Package lib embeds RoadRunner into Go applications: test suites and custom launchers run the plugins
+// container in-process, without the rr binary.
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/roadrunner-server/roadrunner/v2/internal/container"
+	"github.com/roadrunner-server/roadrunner/v2/internal/meta"
+
+	configImpl "github.com/roadrunner-server/config/v2"
+	endure "github.com/roadrunner-server/endure/pkg/container"
+	"github.com/roadrunner-server/errors"
+	"github.com/roadrunner-server/resetter/v2"
+)
+
+// State of the RoadRunner instance.
+type State int32
+
+const (
+	// Initialized instance is ready to serve.
+	Initialized State = iota
+	// Serving instance runs all plugins.
+	Serving
+	// Stopping instance waits for the plugins to stop.
+	Stopping
+	// Stopped instance can't be served again.
+	Stopped
+	// Failed instance was stopped because of a plugin error.
+	Failed
+)
+
+// String returns the state name.
+func (s State) String() string {
+	switch s {
+	case Initialized:
+		return "initialized"
+	case Serving:
+		return "serving"
+	case Stopping:
+		return "stopping"
+	case Stopped:
+		return "stopped"
+	case Failed:
+		return "failed"
+	}
+
+	return fmt.Sprintf("unknown(%d)", int32(s))
+}
+
+type options struct {
+	override []string
+	plugins  []interface{}
+	exclude  []string
+}
+
+// Option configures the instance.
+type Option func(o *options)
+
+// WithOverrides overrides configuration values, same as the `-o key=value` flags of the rr binary.
+func WithOverrides(override ...string) Option {
+	return func(o *options) {
+		o.override = append(o.override, override...)
+	}
+}
+
+// WithPlugins registers extra plugins along with the compiled-in ones.
+func WithPlugins(plugins ...interface{}) Option {
+	return func(o *options) {
+		o.plugins = append(o.plugins, plugins...)
+	}
+}
+
+// WithoutPlugins does not register the compiled-in plugins by name (temporal, new_relic).
+func WithoutPlugins(names ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, names...)
+	}
+}
+
+// RR is the embedded RoadRunner instance.
+type RR struct {
+	container *endure.Endure
+	resetter  *resetter.Plugin
+	state     int32
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+	// error of the container stop, read after done is closed
+	stopErr error
+	// directory of the configuration written by NewFromBytes, kept until the instance is stopped as the plugins may
+	// read the configuration again
+	cfgDir string
+}
+
+// New creates the instance from the configuration file and initializes all plugins.
+func New(cfgFile string, opts ...Option) (*RR, error) {
+	const op = errors.Op("roadrunner_new")
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	containerCfg, err := container.NewConfig(cfgFile)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	exclude := make([]string, 0, len(o.exclude)+len(containerCfg.DisabledPlugins))
+	exclude = append(exclude, o.exclude...)
+	exclude = append(exclude, containerCfg.DisabledPlugins...)
+
+	plugins, err := container.Select(container.Plugins(), nil, exclude)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	plugins = append(plugins, o.plugins...)
+
+	cfg := &configImpl.Plugin{
+		Path:    cfgFile,
+		Prefix:  "rr",
+		Timeout: containerCfg.GracePeriod,
+		Flags:   o.override,
+		Version: meta.Version(),
+	}
+
+	c, err := container.Build(containerCfg, cfg, plugins)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	rr := &RR{container: c, stop: make(chan struct{}), done: make(chan struct{})}
+
+	for _, p := range plugins {
+		if r, ok := p.(*resetter.Plugin); ok {
+			rr.resetter = r
+		}
+	}
+
+	return rr, nil
+}
+
+// NewFromBytes creates the instance from the configuration content of the format (yaml, json). The content is written
+// to a temporary file, which is removed when the instance is stopped.
+func NewFromBytes(data []byte, format string, opts ...Option) (*RR, error) {
+	const op = errors.Op("roadrunner_new_from_bytes")
+
+	dir, err := os.MkdirTemp("", "rr-embedded-")
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	path := filepath.Join(dir, "rr."+format)
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, errors.E(op, err)
+	}
+
+	rr, err := New(path, opts...)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	rr.cfgDir = dir
+
+	return rr, nil
+}
+
+// Serve serves all plugins until the context is canceled, Stop is called or a plugin fails.
+func (rr *RR) Serve(ctx context.Context) error {
+	const op = errors.Op("roadrunner_serve")
+
+	if !atomic.CompareAndSwapInt32(&rr.state, int32(Initialized), int32(Serving)) {
+		return errors.E(op, fmt.Errorf("can't serve the instance in the %s state", rr.CurrentState()))
+	}
+
+	defer close(rr.done)
+	defer rr.cleanup()
+
+	errCh, err := rr.container.Serve()
+	if err != nil {
+		rr.setState(Failed)
+
+		return errors.E(op, err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-rr.stop:
+	case e := <-errCh:
+		rr.stopErr = rr.container.Stop()
+		rr.setState(Failed)
+
+		return errors.E(op, fmt.Errorf("plugin %s: %w", e.VertexID, e.Error))
+	}
+
+	rr.setState(Stopping)
+	rr.stopErr = rr.container.Stop()
+	rr.setState(Stopped)
+
+	if rr.stopErr != nil {
+		return errors.E(op, rr.stopErr)
+	}
+
+	return nil
+}
+
+// Stop stops serving, waits for the plugins to stop and returns the error of stopping them.
+func (rr *RR) Stop() error {
+	const op = errors.Op("roadrunner_stop")
+
+	rr.stopOnce.Do(func() { close(rr.stop) })
+
+	// was never served
+	if atomic.CompareAndSwapInt32(&rr.state, int32(Initialized), int32(Stopped)) {
+		rr.cleanup()
+
+		return nil
+	}
+
+	<-rr.done
+
+	if rr.stopErr != nil {
+		return errors.E(op, rr.stopErr)
+	}
+
+	return nil
+}
+
+// Reset restarts workers of all plugins registered in the resetter plugin.
+func (rr *RR) Reset() error {
+	const op = errors.Op("roadrunner_reset")
+
+	if rr.resetter == nil {
+		return errors.E(op, errors.Str("resetter plugin is not registered"))
+	}
+
+	r, ok := rr.resetter.RPC().(interface {
+		List(_ bool, list *[]string) error
+		Reset(service string, done *bool) error
+	})
+	if !ok {
+		return errors.E(op, errors.Str("unsupported resetter plugin"))
+	}
+
+	var plugins []string
+	if err := r.List(true, &plugins); err != nil {
+		return errors.E(op, err)
+	}
+
+	for _, p := range plugins {
+		var done bool
+		if err := r.Reset(p, &done); err != nil {
+			return errors.E(op, fmt.Errorf("%s: %w", p, err))
+		}
+	}
+
+	return nil
+}
+
+// CurrentState returns the instance state.
+func (rr *RR) CurrentState() State {
+	return State(atomic.LoadInt32(&rr.state))
+}
+
+// cleanup removes the configuration written by NewFromBytes.
+func (rr *RR) cleanup() {
+	if rr.cfgDir != "" {
+		_ = os.RemoveAll(rr.cfgDir)
+	}
+}
+
+func (rr *RR) setState(s State) {
+	atomic.StoreInt32(&rr.state, int32(s))
+}
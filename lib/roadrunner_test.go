@@ -0,0 +1,150 @@
+package lib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/api/v2/plugins/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+version: "2.7"
+rpc:
+  listen: tcp://127.0.0.1:6101
+probe:
+  value: ${RR_EMBEDDED_TEST_VALUE}
+`
+
+// probe is the extra plugin registered by the application.
+type probe struct {
+	value  string
+	served chan struct{}
+}
+
+func (p *probe) Init(cfg config.Configurer) error {
+	p.value = cfg.Get("probe.value").(string)
+	p.served = make(chan struct{}, 1)
+
+	return nil
+}
+
+func (p *probe) Serve() chan error {
+	p.served <- struct{}{}
+
+	return make(chan error, 1)
+}
+
+func (p *probe) Stop() error { return nil }
+
+func (p *probe) Name() string { return "probe" }
+
+func TestRR_Serve(t *testing.T) {
+	t.Setenv("RR_EMBEDDED_TEST_VALUE", "foo")
+
+	p := &probe{}
+
+	rr, err := NewFromBytes([]byte(testConfig), "yaml", WithPlugins(p), WithOverrides("rpc.listen=tcp://127.0.0.1:6102"))
+	require.NoError(t, err)
+	assert.Equal(t, Initialized, rr.CurrentState())
+	assert.Equal(t, "foo", p.value)
+
+	errCh := make(chan error, 1)
+
+	go func() { errCh <- rr.Serve(context.Background()) }()
+
+	select {
+	case <-p.served:
+	case <-time.After(time.Second * 10):
+		t.Fatal("probe plugin is not served")
+	}
+
+	assert.Eventually(t, func() bool { return rr.CurrentState() == Serving }, time.Second*5, time.Millisecond*10)
+	require.NoError(t, rr.Reset())
+
+	// the configuration can be read again while serving
+	_, err = os.Stat(rr.cfgDir)
+	require.NoError(t, err)
+
+	require.NoError(t, rr.Stop())
+	assert.Equal(t, Stopped, rr.CurrentState())
+	require.NoError(t, <-errCh)
+
+	_, err = os.Stat(rr.cfgDir)
+	assert.True(t, os.IsNotExist(err))
+
+	// stopped instance can't be served again
+	assert.Error(t, rr.Serve(context.Background()))
+}
+
+func TestRR_Context(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: \"2.7\"\nrpc:\n  listen: tcp://127.0.0.1:6103\n"), 0o600))
+
+	rr, err := New(path, WithoutPlugins("temporal"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+
+	require.NoError(t, rr.Serve(ctx))
+	assert.Equal(t, Stopped, rr.CurrentState())
+	assert.NoError(t, rr.Stop())
+}
+
+// stuck plugin does not stop in the grace period.
+type stuck struct{}
+
+func (s *stuck) Init() error { return nil }
+
+func (s *stuck) Serve() chan error { return make(chan error, 1) }
+
+func (s *stuck) Stop() error {
+	time.Sleep(time.Second)
+
+	return nil
+}
+
+func (s *stuck) Name() string { return "stuck" }
+
+func TestRR_StopError(t *testing.T) {
+	cfg := "version: \"2.7\"\nendure:\n  grace_period: 100ms\n"
+
+	rr, err := NewFromBytes([]byte(cfg), "yaml", WithPlugins(&stuck{}), WithoutPlugins("temporal"))
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+
+	go func() { errCh <- rr.Serve(context.Background()) }()
+
+	assert.Eventually(t, func() bool { return rr.CurrentState() == Serving }, time.Second*5, time.Millisecond*10)
+
+	err = rr.Stop()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout")
+	assert.Error(t, <-errCh)
+}
+
+func TestRR_NotServed(t *testing.T) {
+	rr, err := NewFromBytes([]byte("version: \"2.7\"\n"), "yaml")
+	require.NoError(t, err)
+
+	require.NoError(t, rr.Stop())
+	assert.Equal(t, Stopped, rr.CurrentState())
+	assert.Error(t, rr.Serve(context.Background()))
+
+	_, err = os.Stat(rr.cfgDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+
+	_, err = NewFromBytes([]byte("version: \"2.7\"\n"), "yaml", WithoutPlugins("unknown"))
+	assert.Error(t, err)
+}
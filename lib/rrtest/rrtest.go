@@ -0,0 +1,215 @@
+// Package rrtest runs RoadRunner in-process for integration tests of applications built on it: the container is
+// started from the inline configuration on random free ports, workers can be implemented in Go (see Main).
+package rrtest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roadrunner-server/roadrunner/v2/lib"
+
+	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
+	"github.com/spf13/viper"
+)
+
+// defaultTimeout of the start.
+const defaultTimeout = time.Second * 30
+
+// listeners assigned to the free ports: configuration section, address key and address format.
+var listeners = [...]struct{ section, key, format string }{ //nolint:gochecknoglobals
+	{"rpc", "rpc.listen", "tcp://%s"},
+	{"http", "http.address", "%s"},
+	{"grpc", "grpc.listen", "tcp://%s"},
+}
+
+type options struct {
+	override []string
+	plugins  []interface{}
+	worker   string
+	timeout  time.Duration
+}
+
+// Option configures the test server.
+type Option func(o *options)
+
+// WithWorker launches the test binary as the worker, the handler is selected by the name in Main.
+func WithWorker(name string) Option {
+	return func(o *options) {
+		o.worker = name
+	}
+}
+
+// WithOverrides overrides configuration values, same as the `-o key=value` flags of the rr binary.
+func WithOverrides(override ...string) Option {
+	return func(o *options) {
+		o.override = append(o.override, override...)
+	}
+}
+
+// WithPlugins registers extra plugins.
+func WithPlugins(plugins ...interface{}) Option {
+	return func(o *options) {
+		o.plugins = append(o.plugins, plugins...)
+	}
+}
+
+// WithTimeout sets the time to wait for the server to become ready (30s by default).
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// Server is the running RoadRunner instance.
+type Server struct {
+	RR *lib.RR
+	// RPC client connected to the rpc plugin.
+	RPC *rpc.Client
+
+	addresses map[string]string
+}
+
+// Start starts RoadRunner from the YAML configuration and waits until all listeners accept connections. RPC, HTTP and
+// gRPC listen on random free ports. The server is stopped on the test cleanup.
+func Start(t testing.TB, cfg string, opts ...Option) *Server {
+	t.Helper()
+
+	o := &options{timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	if err := v.ReadConfig(strings.NewReader(cfg)); err != nil {
+		t.Fatalf("rrtest: invalid configuration: %v", err)
+	}
+
+	s := &Server{addresses: make(map[string]string, len(listeners))}
+	override := make([]string, 0, len(listeners)+len(o.override)+2)
+
+	for _, l := range listeners {
+		// rpc is always enabled for the client
+		if l.section != "rpc" && !v.IsSet(l.section) {
+			continue
+		}
+
+		addr, err := freeAddress()
+		if err != nil {
+			t.Fatalf("rrtest: %v", err)
+		}
+
+		s.addresses[l.section] = addr
+		override = append(override, l.key+"="+fmt.Sprintf(l.format, addr))
+	}
+
+	if o.worker != "" {
+		override = append(override,
+			fmt.Sprintf("server.command=%s -test.run=^$", os.Args[0]),
+			fmt.Sprintf("server.env.%s=%s", EnvWorker, o.worker),
+		)
+	}
+
+	rr, err := lib.NewFromBytes([]byte(cfg), "yaml",
+		lib.WithOverrides(append(override, o.override...)...),
+		lib.WithPlugins(o.plugins...),
+	)
+	if err != nil {
+		t.Fatalf("rrtest: %v", err)
+	}
+
+	s.RR = rr
+
+	errCh := make(chan error, 1)
+
+	go func() { errCh <- rr.Serve(context.Background()) }()
+
+	t.Cleanup(func() {
+		if s.RPC != nil {
+			_ = s.RPC.Close()
+		}
+
+		_ = rr.Stop()
+
+		if err := <-errCh; err != nil {
+			t.Errorf("rrtest: %v", err)
+		}
+	})
+
+	if err = s.wait(errCh, o.timeout); err != nil {
+		t.Fatalf("rrtest: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", s.addresses["rpc"])
+	if err != nil {
+		t.Fatalf("rrtest: %v", err)
+	}
+
+	s.RPC = rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	return s
+}
+
+// HTTPAddr returns the http plugin base URL: http://127.0.0.1:port.
+func (s *Server) HTTPAddr() string {
+	return "http://" + s.addresses["http"]
+}
+
+// GRPCAddr returns the grpc plugin address: 127.0.0.1:port.
+func (s *Server) GRPCAddr() string {
+	return s.addresses["grpc"]
+}
+
+// RPCAddr returns the rpc plugin address: tcp://127.0.0.1:port.
+func (s *Server) RPCAddr() string {
+	return "tcp://" + s.addresses["rpc"]
+}
+
+// wait waits until all listeners accept connections, fails if RoadRunner stops serving before that.
+func (s *Server) wait(errCh chan error, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for _, addr := range s.addresses {
+		for {
+			conn, err := net.DialTimeout("tcp", addr, time.Second)
+			if err == nil {
+				_ = conn.Close()
+
+				break
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("not ready after %s: %w", timeout, err)
+			}
+
+			select {
+			case err = <-errCh:
+				// the error is reported once
+				errCh <- err
+
+				return fmt.Errorf("stopped before ready: %v", err)
+			case <-time.After(time.Millisecond * 20):
+			}
+		}
+	}
+
+	return nil
+}
+
+func freeAddress() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = l.Close() }()
+
+	return l.Addr().String(), nil
+}
@@ -0,0 +1,79 @@
+package rrtest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/roadrunner-server/roadrunner/v2/pkg/worker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	Main(m, map[string]worker.Handler{
+		"echo": worker.HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Method", r.Method)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.Copy(w, r.Body)
+		})),
+		"fail": func(*worker.Payload) (*worker.Payload, error) {
+			return nil, errors.New("worker failed")
+		},
+	})
+}
+
+const httpConfig = `
+version: "2.7"
+server:
+  command: "php worker.php"
+http:
+  pool:
+    num_workers: 2
+logs:
+  level: error
+`
+
+func TestStart_HTTP(t *testing.T) {
+	s := Start(t, httpConfig, WithWorker("echo"))
+
+	rsp, err := http.Post(s.HTTPAddr()+"/foo", "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(rsp.Body)
+	require.NoError(t, err)
+	_ = rsp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, rsp.StatusCode)
+	assert.Equal(t, "POST", rsp.Header.Get("X-Method"))
+	assert.Equal(t, "hello", string(body))
+
+	var done bool
+	require.NoError(t, s.RPC.Call("resetter.Reset", "http", &done))
+
+	var list []string
+	require.NoError(t, s.RPC.Call("informer.List", true, &list))
+	assert.Contains(t, list, "http")
+}
+
+func TestStart_WorkerError(t *testing.T) {
+	s := Start(t, httpConfig, WithWorker("fail"), WithOverrides("http.pool.debug=true"))
+
+	rsp, err := http.Get(s.HTTPAddr())
+	require.NoError(t, err)
+	_ = rsp.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, rsp.StatusCode)
+}
+
+func TestStart_RPC(t *testing.T) {
+	s := Start(t, "version: \"2.7\"\n")
+	assert.Empty(t, s.GRPCAddr())
+	assert.Contains(t, s.RPCAddr(), "tcp://127.0.0.1:")
+
+	var list []string
+	require.NoError(t, s.RPC.Call("informer.List", true, &list))
+}
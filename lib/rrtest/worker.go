@@ -0,0 +1,42 @@
+package rrtest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/roadrunner-server/roadrunner/v2/pkg/worker"
+)
+
+// EnvWorker is set for the worker process to the worker name.
+const EnvWorker = "RR_TEST_WORKER"
+
+// Main runs the worker when the test binary is launched by RoadRunner (see WithWorker), tests otherwise:
+//
+//	func TestMain(m *testing.M) {
+//		rrtest.Main(m, map[string]worker.Handler{"echo": worker.HTTP(echoHandler)})
+//	}
+func Main(m *testing.M, workers map[string]worker.Handler) {
+	name := os.Getenv(EnvWorker)
+	if name == "" {
+		os.Exit(m.Run())
+	}
+
+	h, ok := workers[name]
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown worker `%s`\n", name)
+		os.Exit(1)
+	}
+
+	rl, err := worker.NewRelay()
+	if err == nil {
+		err = worker.Serve(rl, h)
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
@@ -0,0 +1,76 @@
+// Package external defines the protocol between RoadRunner and out-of-process plugins, and helps to implement such
+// plugins in Go. RoadRunner launches the plugin binary (or connects to the running one) and talks to it over goridge
+// RPC: the plugin serves the Service RPC methods (handshake, health check, HTTP middleware) and its own RPC methods,
+// which are proxied via the `external.Call` RPC method of RoadRunner.
+package external
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// ProtocolVersion is incremented on incompatible protocol changes.
+	ProtocolVersion int = 1
+	// EnvAddress is the address (tcp://127.0.0.1:7001, unix:///tmp/plugin.sock) the launched plugin must listen on.
+	EnvAddress string = "RR_PLUGIN_ADDRESS"
+	// Service is the RPC service implemented by every external plugin.
+	Service string = "RRPlugin"
+
+	// MethodHandshake exchanges HandshakeRequest and Manifest.
+	MethodHandshake string = Service + ".Handshake"
+	// MethodHealth checks the plugin health.
+	MethodHealth string = Service + ".Health"
+	// MethodMiddleware handles MiddlewareRequest.
+	MethodMiddleware string = Service + ".Middleware"
+)
+
+// HandshakeRequest is sent by RoadRunner right after the connection.
+type HandshakeRequest struct {
+	Protocol int
+	// Name of the plugin in the RoadRunner configuration.
+	Name      string
+	RRVersion string
+}
+
+// Manifest describes what the plugin registers in RoadRunner.
+type Manifest struct {
+	Protocol int
+	// RPC lists plugin methods (Service.Method) available via `external.Call`.
+	RPC []string
+	// Middleware is true when the plugin handles HTTP requests as the `external` http middleware.
+	Middleware bool
+	// Depends lists RoadRunner plugins the plugin requires. The handshake fails when a dependency is not registered in
+	// the container (e.g. excluded by --exclude or endure.disabled_plugins) or has no configuration section.
+	Depends []string
+}
+
+// MiddlewareRequest contains the HTTP request line and headers, the body is not passed.
+type MiddlewareRequest struct {
+	Method     string
+	URI        string
+	RemoteAddr string
+	Header     http.Header
+}
+
+// MiddlewareResponse either passes the request further (Status is 0) or responds to it.
+type MiddlewareResponse struct {
+	// Status of the response, 0 passes the request to the next handler.
+	Status int
+	// Header is added to the response.
+	Header http.Header
+	// RequestHeader is added to the request passed to the next handler.
+	RequestHeader http.Header
+	// Body of the response when Status is set.
+	Body []byte
+}
+
+// CallRequest is the argument of the `external.Call` RoadRunner RPC method.
+type CallRequest struct {
+	// Plugin name in the RoadRunner configuration.
+	Plugin string `json:"plugin"`
+	// Method of the plugin (Service.Method), declared in the manifest.
+	Method string `json:"method"`
+	// Payload is passed to the method as is.
+	Payload json.RawMessage `json:"payload"`
+}
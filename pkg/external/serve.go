@@ -0,0 +1,128 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"strings"
+
+	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
+)
+
+// Plugin is implemented by the external plugin.
+type Plugin interface {
+	// Manifest is returned on the handshake.
+	Manifest(req *HandshakeRequest) Manifest
+	// Health returns an error when the plugin is not able to serve.
+	Health() error
+	// Middleware handles HTTP requests when the manifest declares the middleware.
+	Middleware(req *MiddlewareRequest, resp *MiddlewareResponse) error
+}
+
+// Serve listens on the address passed by RoadRunner (EnvAddress) and serves the plugin. Services are registered by
+// name, their methods receive and return JSON payloads: func (s *Service) Method(in []byte, out *[]byte) error.
+func Serve(p Plugin, services map[string]interface{}) error {
+	addr := os.Getenv(EnvAddress)
+	if addr == "" {
+		return fmt.Errorf("%s is not set, the plugin should be started by RoadRunner", EnvAddress)
+	}
+
+	l, err := Listen(addr)
+	if err != nil {
+		return err
+	}
+
+	return ServeListener(l, p, services)
+}
+
+// ServeListener serves the plugin on the listener until it is closed.
+func ServeListener(l net.Listener, p Plugin, services map[string]interface{}) error {
+	srv := rpc.NewServer()
+
+	if err := srv.RegisterName(Service, &protocol{plugin: p}); err != nil {
+		return err
+	}
+
+	for name, svc := range services {
+		if err := srv.RegisterName(name, svc); err != nil {
+			return err
+		}
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return err
+		}
+
+		go srv.ServeCodec(goridgeRpc.NewCodec(conn))
+	}
+}
+
+// Listen creates listener from the address DSN: tcp://127.0.0.1:7001 or unix:///tmp/plugin.sock.
+func Listen(addr string) (net.Listener, error) {
+	network, address, err := parseAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == "unix" {
+		_ = os.Remove(address)
+	}
+
+	return net.Listen(network, address)
+}
+
+// Dial connects to the address DSN: tcp://127.0.0.1:7001 or unix:///tmp/plugin.sock.
+func Dial(addr string) (net.Conn, error) {
+	network, address, err := parseAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return net.Dial(network, address)
+}
+
+func parseAddress(addr string) (string, string, error) {
+	network, address, ok := strings.Cut(addr, "://")
+	if !ok || (network != "tcp" && network != "unix") || address == "" {
+		return "", "", fmt.Errorf("invalid address `%s` (tcp://127.0.0.1:7001, unix:///tmp/plugin.sock)", addr)
+	}
+
+	return network, address, nil
+}
+
+// protocol is the Service RPC service.
+type protocol struct {
+	plugin Plugin
+}
+
+// Handshake returns the plugin manifest.
+func (p *protocol) Handshake(req HandshakeRequest, out *Manifest) error {
+	*out = p.plugin.Manifest(&req)
+	out.Protocol = ProtocolVersion
+
+	return nil
+}
+
+// Health checks the plugin health.
+func (p *protocol) Health(_ bool, ok *bool) error {
+	if err := p.plugin.Health(); err != nil {
+		return err
+	}
+
+	*ok = true
+
+	return nil
+}
+
+// Middleware handles the HTTP request.
+func (p *protocol) Middleware(req MiddlewareRequest, resp *MiddlewareResponse) error {
+	return p.plugin.Middleware(&req, resp)
+}
@@ -0,0 +1,25 @@
+package external_test
+
+import (
+	"testing"
+
+	"github.com/roadrunner-server/roadrunner/v2/pkg/external"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe_NotLaunched(t *testing.T) {
+	t.Setenv(external.EnvAddress, "")
+
+	assert.Error(t, external.Serve(nil, nil))
+}
+
+func TestListen_InvalidAddress(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:7001", "udp://127.0.0.1:7001", "unix://"} {
+		_, err := external.Listen(addr)
+		assert.Error(t, err, addr)
+
+		_, err = external.Dial(addr)
+		assert.Error(t, err, addr)
+	}
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// httpRequest is the request context sent by the http plugin.
+type httpRequest struct {
+	RemoteAddr string      `json:"remoteAddr"`
+	Protocol   string      `json:"protocol"`
+	Method     string      `json:"method"`
+	URI        string      `json:"uri"`
+	Header     http.Header `json:"headers"`
+}
+
+// httpResponse is the response context expected by the http plugin.
+type httpResponse struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers"`
+}
+
+// HTTP adapts the http.Handler to the http plugin worker. Parsed form bodies are passed as JSON.
+func HTTP(h http.Handler) Handler {
+	return func(p *Payload) (*Payload, error) {
+		var in httpRequest
+		if err := json.Unmarshal(p.Context, &in); err != nil {
+			return nil, err
+		}
+
+		req := httptest.NewRequest(in.Method, in.URI, bytes.NewReader(p.Body))
+		req.RemoteAddr = in.RemoteAddr
+		req.Header = in.Header
+
+		if req.Header == nil {
+			req.Header = http.Header{}
+		}
+
+		if strings.HasPrefix(in.Protocol, "HTTP/") {
+			req.Proto = in.Protocol
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return HTTPResponse(rec.Code, rec.Header(), rec.Body.Bytes())
+	}
+}
+
+// HTTPResponse creates the http plugin response payload.
+func HTTPResponse(status int, header http.Header, body []byte) (*Payload, error) {
+	ctx, err := json.Marshal(httpResponse{Status: status, Headers: header})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Payload{Context: ctx, Body: body}, nil
+}
@@ -0,0 +1,146 @@
+// Package worker implements the RoadRunner worker side of the goridge protocol in Go.
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/roadrunner-server/goridge/v3/pkg/frame"
+	"github.com/roadrunner-server/goridge/v3/pkg/pipe"
+	"github.com/roadrunner-server/goridge/v3/pkg/relay"
+	"github.com/roadrunner-server/goridge/v3/pkg/socket"
+)
+
+const (
+	// EnvRelay is the relay DSN passed by the server plugin: pipes, tcp://127.0.0.1:7000, unix:///tmp/rr.sock.
+	EnvRelay = "RR_RELAY"
+	// EnvMode is the plugin the worker is started for (http, jobs, grpc, ...).
+	EnvMode = "RR_MODE"
+)
+
+// Payload exchanged with RoadRunner: JSON context (headers, status) and body.
+type Payload struct {
+	Context []byte
+	Body    []byte
+}
+
+// Handler handles the payload sent by RoadRunner to the worker, returned error is sent to RoadRunner as a soft error.
+type Handler func(p *Payload) (*Payload, error)
+
+// NewRelay connects to RoadRunner using the relay from the EnvRelay variable (pipes by default).
+func NewRelay() (relay.Relay, error) {
+	dsn := os.Getenv(EnvRelay)
+	if dsn == "" || dsn == "pipes" {
+		return pipe.NewPipeRelay(os.Stdin, os.Stdout), nil
+	}
+
+	network, address, ok := strings.Cut(dsn, "://")
+	if !ok || (network != "tcp" && network != "unix") {
+		return nil, fmt.Errorf("invalid relay `%s` (pipes, tcp://127.0.0.1:7000, unix:///tmp/rr.sock)", dsn)
+	}
+
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return socket.NewSocketRelay(conn), nil
+}
+
+// Serve serves the handler until RoadRunner stops the worker or closes the relay.
+func Serve(rl relay.Relay, h Handler) error {
+	for {
+		fr := frame.NewFrame()
+
+		if err := rl.Receive(fr); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if fr.ReadFlags()&frame.CONTROL != 0 {
+			stop, err := control(rl, fr.Payload())
+			if err != nil || stop {
+				return err
+			}
+
+			continue
+		}
+
+		var offset uint32
+		if opts := fr.ReadOptions(fr.Header()); len(opts) > 0 {
+			offset = opts[0]
+		}
+
+		data := fr.Payload()
+		if int(offset) > len(data) {
+			return fmt.Errorf("bad payload, context length %d is out of range", offset)
+		}
+
+		rsp, err := h(&Payload{Context: data[:offset], Body: data[offset:]})
+		if err != nil {
+			if err = send(rl, frame.ERROR, nil, []byte(err.Error())); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err = send(rl, frame.CodecJSON, rsp.Context, rsp.Body); err != nil {
+			return err
+		}
+	}
+}
+
+type pidCommand struct {
+	Pid int `json:"pid"`
+}
+
+type stopCommand struct {
+	Stop bool `json:"stop"`
+}
+
+// control replies to the pid request, returns true on the stop command.
+func control(rl relay.Relay, data []byte) (bool, error) {
+	var stop stopCommand
+	if err := json.Unmarshal(data, &stop); err == nil && stop.Stop {
+		return true, nil
+	}
+
+	pid, err := json.Marshal(pidCommand{Pid: os.Getpid()})
+	if err != nil {
+		return false, err
+	}
+
+	fr := frame.NewFrame()
+	fr.WriteVersion(fr.Header(), frame.Version1)
+	fr.WriteFlags(fr.Header(), frame.CONTROL, frame.CodecJSON)
+	fr.WritePayloadLen(fr.Header(), uint32(len(pid)))
+	fr.WritePayload(pid)
+	fr.WriteCRC(fr.Header())
+
+	return false, rl.Send(fr)
+}
+
+func send(rl relay.Relay, flags byte, ctx, body []byte) error {
+	data := make([]byte, 0, len(ctx)+len(body))
+	data = append(data, ctx...)
+	data = append(data, body...)
+
+	fr := frame.NewFrame()
+	fr.WriteVersion(fr.Header(), frame.Version1)
+	fr.WriteFlags(fr.Header(), flags)
+	fr.WriteOptions(fr.HeaderPtr(), uint32(len(ctx)))
+	fr.WritePayloadLen(fr.Header(), uint32(len(data)))
+	fr.WritePayload(data)
+	fr.WriteCRC(fr.Header())
+
+	return rl.Send(fr)
+}
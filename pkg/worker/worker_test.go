@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/roadrunner-server/goridge/v3/pkg/frame"
+	"github.com/roadrunner-server/goridge/v3/pkg/relay"
+	"github.com/roadrunner-server/goridge/v3/pkg/socket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exec(t *testing.T, rl relay.Relay, ctx, body string) *frame.Frame {
+	require.NoError(t, send(rl, frame.CodecJSON, []byte(ctx), []byte(body)))
+
+	fr := frame.NewFrame()
+	require.NoError(t, rl.Receive(fr))
+
+	return fr
+}
+
+func TestServe(t *testing.T) {
+	rrConn, workerConn := net.Pipe()
+	rl := socket.NewSocketRelay(rrConn)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- Serve(socket.NewSocketRelay(workerConn), func(p *Payload) (*Payload, error) {
+			if string(p.Body) == "fail" {
+				return nil, errors.New("failed")
+			}
+
+			return p, nil
+		})
+	}()
+
+	// pid handshake
+	_, err := control(rl, []byte(`{"pid":1}`))
+	require.NoError(t, err)
+
+	fr := frame.NewFrame()
+	require.NoError(t, rl.Receive(fr))
+	assert.NotZero(t, fr.ReadFlags()&frame.CONTROL)
+
+	var pid pidCommand
+	require.NoError(t, json.Unmarshal(fr.Payload(), &pid))
+	assert.Equal(t, os.Getpid(), pid.Pid)
+
+	fr = exec(t, rl, `{"a":1}`, "body")
+	assert.Equal(t, []uint32{7}, fr.ReadOptions(fr.Header()))
+	assert.Equal(t, `{"a":1}body`, string(fr.Payload()))
+
+	fr = exec(t, rl, "", "fail")
+	assert.NotZero(t, fr.ReadFlags()&frame.ERROR)
+	assert.Equal(t, "failed", string(fr.Payload()))
+
+	data, err := json.Marshal(stopCommand{Stop: true})
+	require.NoError(t, err)
+
+	fr = frame.NewFrame()
+	fr.WriteVersion(fr.Header(), frame.Version1)
+	fr.WriteFlags(fr.Header(), frame.CONTROL)
+	fr.WritePayloadLen(fr.Header(), uint32(len(data)))
+	fr.WritePayload(data)
+	fr.WriteCRC(fr.Header())
+	require.NoError(t, rl.Send(fr))
+
+	assert.NoError(t, <-done)
+}
+
+func TestHTTP(t *testing.T) {
+	h := HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Agent", r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path))
+	}))
+
+	rsp, err := h(&Payload{
+		Context: []byte(`{"method":"PUT","uri":"http://localhost/foo?a=b","headers":{"User-Agent":["test"]}}`),
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":202,"headers":{"X-Agent":["test"]}}`, string(rsp.Context))
+	assert.Equal(t, "PUT /foo", string(rsp.Body))
+}